@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"auth/internal/entity"
+	"auth/internal/http/lib/jwt"
+	repository "auth/internal/repository/postgres"
+	"auth/internal/service"
+	"auth/internal/storage/postgres"
+)
+
+const clientUsage = `Usage: auth client <command> [flags]
+
+Commands:
+  create  register an OAuth client
+  list    list OAuth clients
+
+Run "auth client <command> -h" for the flags of a command.
+`
+
+type clientCommand func(ctx context.Context, svc *service.Service) error
+
+func client(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprint(os.Stderr, clientUsage)
+		return exitCode(2)
+	}
+
+	name, args := args[0], args[1:]
+	fs := flag.NewFlagSet("auth client "+name, flag.ExitOnError)
+
+	var cmd clientCommand
+	switch name {
+	case "create":
+		cmd = clientCreate(fs)
+	case "list":
+		cmd = clientList
+	default:
+		fmt.Fprintf(os.Stderr, "unknown client command %q\n\n%s", name, clientUsage)
+		return exitCode(2)
+	}
+
+	cfg, log, err := setup(fs.Name(), args, fs, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := jwt.New(cfg.JWT)
+	if err != nil {
+		log.Error("failed to load signing keys", "error", err)
+		return err
+	}
+
+	db, err := postgres.NewPool(cfg.Postgres, log)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = postgres.DBClose(db, log)
+	}()
+
+	svc := service.New(db, log, repository.New(db), tokens, cfg.JWT, cfg.OAuth)
+
+	if err = cmd(context.Background(), svc); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return err
+	}
+
+	return nil
+}
+
+func clientCreate(fs *flag.FlagSet) clientCommand {
+	name := fs.String("name", "", "name of the client, e.g. the job using it")
+	scopes := fs.String("scopes", "", "comma-separated scopes the client may request: "+strings.Join(entity.Scopes, ", "))
+	redirectURIs := fs.String("redirect-uris", "", "comma-separated redirect URIs for the authorization code grant")
+	public := fs.Bool("public", false, "register a public client without a secret, e.g. a browser or mobile app")
+	grantTypes := fs.String("grant-types", "", "comma-separated grants: "+strings.Join(entity.GrantTypes, ", ")+"; derived from the client type and redirect URIs when empty")
+	accessTTL := fs.Duration("access-ttl", 0, "access token lifetime for the client, 0 keeps the configured one")
+	refreshTTL := fs.Duration("refresh-ttl", 0, "refresh token lifetime for the client, 0 keeps the configured one")
+
+	return func(ctx context.Context, svc *service.Service) error {
+		if *name == "" {
+			return errors.New("--name is required")
+		}
+
+		c := &entity.OAuthClient{
+			Name:         *name,
+			Scopes:       splitList(*scopes),
+			RedirectURIs: splitList(*redirectURIs),
+			GrantTypes:   splitList(*grantTypes),
+			Public:       *public,
+			AccessTTL:    *accessTTL,
+			RefreshTTL:   *refreshTTL,
+		}
+
+		secret, err := svc.CreateClient(ctx, c)
+		if errors.Is(err, service.InvalidScopeError) {
+			return fmt.Errorf("scopes must be among %s", strings.Join(entity.Scopes, ", "))
+		}
+
+		if errors.Is(err, service.InvalidRedirectURIError) {
+			return errors.New("redirect URIs must be absolute without a fragment, and the authorization code grant needs at least one")
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if c.Public {
+			fmt.Printf("client_id:     %s\n", c.ID)
+			return nil
+		}
+
+		fmt.Printf("client_id:     %s\nclient_secret: %s\n", c.ID, secret)
+		fmt.Fprintln(os.Stderr, "store the secret now, it cannot be shown again")
+		return nil
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func clientList(ctx context.Context, svc *service.Service) error {
+	clients, err := svc.ListClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLIENT ID\tNAME\tTYPE\tGRANT TYPES\tSCOPES\tREDIRECT URIS\tCREATED AT\tDISABLED AT")
+	for _, c := range clients {
+		disabledAt := ""
+		if c.DisabledAt.Valid {
+			disabledAt = c.DisabledAt.Time.Format(time.RFC3339)
+		}
+
+		kind := "confidential"
+		if c.Public {
+			kind = "public"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			c.ID, c.Name, kind, strings.Join(c.GrantTypes, " "), strings.Join(c.Scopes, " "), strings.Join(c.RedirectURIs, " "),
+			c.CreatedAt.Format(time.RFC3339), disabledAt)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"auth/internal/http/lib/jwt"
+	repository "auth/internal/repository/postgres"
+	"auth/internal/service"
+	"auth/internal/storage/postgres"
+)
+
+const keysUsage = `Usage: auth keys <command> [flags]
+
+Commands:
+  list    list access token signing keys and their state
+  rotate  make a new key active, --retire-previous rejects tokens of the old key at once
+
+Run "auth keys <command> -h" for the flags of a command.
+`
+
+type keysCommand func(ctx context.Context, svc *service.Service) error
+
+func keys(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprint(os.Stderr, keysUsage)
+		return exitCode(2)
+	}
+
+	name, args := args[0], args[1:]
+	fs := flag.NewFlagSet("auth keys "+name, flag.ExitOnError)
+
+	var cmd keysCommand
+	switch name {
+	case "list":
+		cmd = keysList
+	case "rotate":
+		cmd = keysRotate(fs)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown keys command %q\n\n%s", name, keysUsage)
+		return exitCode(2)
+	}
+
+	cfg, log, err := setup(fs.Name(), args, fs, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := jwt.New(cfg.JWT)
+	if err != nil {
+		log.Error("failed to load signing keys", "error", err)
+		return err
+	}
+
+	db, err := postgres.NewPool(cfg.Postgres, log)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = postgres.DBClose(db, log)
+	}()
+
+	ctx := context.Background()
+	svc := service.New(db, log, repository.New(db), tokens, cfg.JWT, cfg.OAuth)
+
+	if err = svc.LoadKeys(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return err
+	}
+
+	if err = cmd(ctx, svc); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return err
+	}
+
+	return nil
+}
+
+func keysList(ctx context.Context, svc *service.Service) error {
+	keys, err := svc.ListKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KID\tALGORITHM\tSTATE\tCREATED AT\tROTATED AT\tRETIRED AT")
+	for _, k := range keys {
+		createdAt, rotatedAt, retiredAt := "", "", ""
+		if !k.CreatedAt.IsZero() {
+			createdAt = k.CreatedAt.Format(time.RFC3339)
+		}
+		if k.RotatedAt.Valid {
+			rotatedAt = k.RotatedAt.Time.Format(time.RFC3339)
+		}
+		if k.RetiredAt.Valid {
+			retiredAt = k.RetiredAt.Time.Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", k.ID, k.Algorithm, k.State, createdAt, rotatedAt, retiredAt)
+	}
+
+	return w.Flush()
+}
+
+func keysRotate(fs *flag.FlagSet) keysCommand {
+	retire := fs.Bool("retire-previous", false, "retire the previous key instead of keeping it for verification")
+
+	return func(ctx context.Context, svc *service.Service) error {
+		k, err := svc.RotateKey(ctx, *retire)
+		if errors.Is(err, service.KeyStoreReadOnlyError) {
+			return errors.New("rotation needs jwt.key_store: database")
+		}
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("key %s (%s) is now active\n", k.ID, k.Algorithm)
+		return nil
+	}
+}
@@ -1,20 +1,28 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log/slog"
-	"net/http"
 	"os"
-	"time"
+	"strings"
 
-	"auth/internal/http/handler"
-	router "auth/internal/http/router/chi"
-	repository "auth/internal/repository/postgres"
-	"auth/internal/service"
-	"auth/internal/storage/postgres"
-
-	"github.com/go-chi/chi/v5"
+	"auth/internal/config"
+	"auth/internal/logger"
 )
 
+const usage = `Usage:
+  auth [flags]                   start the HTTP server
+  auth migrate [flags] <command> manage the database schema
+  auth user <command> [flags]    manage user accounts offline
+  auth keys <command> [flags]    list or rotate access token signing keys
+  auth client <command> [flags]  manage OAuth clients
+
+Run "auth <command> -h" for the flags of a command.
+`
+
 // @title Auth API
 // @version 1.0
 // @description This is the authentication API service.
@@ -28,50 +36,69 @@ import (
 // @BasePath /
 // @schemes http
 func main() {
-	logHandler := slog.NewTextHandler(
-		os.Stdout,
-		&slog.HandlerOptions{
-			Level: slog.LevelDebug,
-		},
-	)
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
 
-	log := slog.New(logHandler)
-	log.Info("init logger")
+	var err error
+	switch command {
+	case "serve":
+		err = serve(args)
+	case "migrate":
+		err = migrate(args)
+	case "user":
+		err = user(args)
+	case "keys":
+		err = keys(args)
+	case "client":
+		err = client(args)
+	case "help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+
+	var exitErr exitCode
+	if errors.As(err, &exitErr) {
+		os.Exit(int(exitErr))
+	}
 
-	db, err := postgres.NewPool(log)
 	if err != nil {
 		os.Exit(1)
 	}
+}
+
+// exitCode is returned by commands that already reported the problem and
+// only need the process to exit with a specific status.
+type exitCode int
 
-	defer func() {
-		err = postgres.DBClose(db, log)
-		if err != nil {
-			os.Exit(1)
-		}
-
-		log.Info("success close database")
-	}()
-
-	postgresRepos := repository.New(db)
-	services := service.New(db, log, postgresRepos)
-	handlers := handler.New(db, log, services)
-
-	chiRouter := chi.NewRouter()
-	router.New(chiRouter, handlers)
-
-	log.Info("start auth service", "address", "localhost:8085")
-	server := &http.Server{
-		Addr:         "localhost:8085",
-		Handler:      chiRouter,
-		ReadTimeout:  4 * time.Second,
-		WriteTimeout: 4 * time.Second,
-		IdleTimeout:  60 * time.Second,
+func (e exitCode) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+// setup loads the configuration from args and builds the logger. Commands
+// that print results to stdout pass os.Stderr as logOut.
+func setup(name string, args []string, fs *flag.FlagSet, logOut io.Writer) (*config.Config, *slog.Logger, error) {
+	if fs == nil {
+		fs = flag.NewFlagSet(name, flag.ExitOnError)
 	}
 
-	if err = server.ListenAndServe(); err != nil {
-		log.Error("failed to start server", "error", err)
-		os.Exit(1)
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, exitCode(2)
 	}
 
-	log.Error("server stopped")
+	log, err := logger.New(cfg.Log, logOut)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, exitCode(2)
+	}
+
+	log.Info("init logger", "env", cfg.Env, "level", cfg.Log.Level, "format", cfg.Log.Format)
+
+	return cfg, log, nil
 }
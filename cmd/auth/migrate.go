@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"auth/internal/migrator"
+	"auth/internal/storage/postgres"
+	"auth/migrations"
+)
+
+const migrateUsage = `Usage: auth migrate [flags] <command>
+
+Commands:
+  up             apply all pending migrations
+  down           roll back the most recently applied migration
+  status         list migrations and whether they are applied
+  goto <version> migrate up or down to the given version, 0 rolls back everything
+
+Flags:
+`
+
+func migrate(args []string) error {
+	fs := flag.NewFlagSet("auth migrate", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), migrateUsage)
+		fs.PrintDefaults()
+	}
+
+	cfg, log, err := setup("auth migrate", args, fs, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitCode(2)
+	}
+
+	db, err := postgres.NewPool(cfg.Postgres, log)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = postgres.DBClose(db, log)
+	}()
+
+	m, err := migrator.New(db, log, migrations.FS)
+	if err != nil {
+		log.Error("failed to load migrations", "error", err)
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch command := fs.Arg(0); command {
+	case "up":
+		err = m.Up(ctx)
+	case "down":
+		err = m.Down(ctx)
+	case "goto":
+		var version int64
+		version, err = strconv.ParseInt(fs.Arg(1), 10, 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid version %q\n", fs.Arg(1))
+			return exitCode(2)
+		}
+		err = m.Goto(ctx, version)
+	case "status":
+		err = printStatus(ctx, m)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n\n", command)
+		fs.Usage()
+		return exitCode(2)
+	}
+
+	if err != nil {
+		log.Error("migration failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func printStatus(ctx context.Context, m *migrator.Migrator) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+	for _, st := range statuses {
+		state, appliedAt := "pending", ""
+		if st.Applied {
+			state, appliedAt = "applied", st.AppliedAt.Format(time.RFC3339)
+		}
+
+		if st.ChecksumMismatch {
+			state = "modified"
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", st.Version, st.Name, state, appliedAt)
+	}
+
+	return w.Flush()
+}
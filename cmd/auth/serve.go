@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"auth/internal/app"
+	"auth/internal/config"
+	"auth/internal/health"
+	"auth/internal/http/handler"
+	"auth/internal/http/lib/jwt"
+	router "auth/internal/http/router/chi"
+	"auth/internal/metrics"
+	"auth/internal/migrator"
+	repository "auth/internal/repository/postgres"
+	"auth/internal/service"
+	"auth/internal/storage/postgres"
+	"auth/migrations"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const readinessTimeout = 2 * time.Second
+
+func serve(args []string) error {
+	cfg, log, err := setup("auth", args, nil, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err = run(ctx, cfg, log); err != nil {
+		log.Error("server stopped", "error", err)
+		return err
+	}
+
+	log.Info("server stopped")
+	return nil
+}
+
+func run(ctx context.Context, cfg *config.Config, log *slog.Logger) error {
+	db, err := postgres.NewPool(cfg.Postgres, log)
+	if err != nil {
+		return err
+	}
+
+	schema, err := migrator.New(db, log, migrations.FS)
+	if err != nil {
+		_ = postgres.DBClose(db, log)
+		return err
+	}
+
+	if err = schema.Check(ctx); err != nil {
+		log.Error("refusing to start, run `auth migrate up` first", "error", err)
+		_ = postgres.DBClose(db, log)
+		return err
+	}
+
+	metrics.RegisterPool(db)
+
+	tokens, err := jwt.New(cfg.JWT)
+	if err != nil {
+		log.Error("failed to load signing keys", "error", err)
+		_ = postgres.DBClose(db, log)
+		return err
+	}
+
+	checker := health.New(readinessTimeout)
+	checker.Add("postgres", db.Ping)
+	checker.Add("migrations", schema.Check)
+	checker.Add("signing_keys", func(context.Context) error {
+		return tokens.Ready()
+	})
+
+	postgresRepos := repository.New(db)
+	services := service.New(db, log, postgresRepos, tokens, cfg.JWT, cfg.OAuth)
+
+	if err = services.LoadRevokedTokens(ctx); err != nil {
+		log.Error("failed to load revoked tokens", "error", err)
+		_ = postgres.DBClose(db, log)
+		return err
+	}
+
+	if err = services.LoadKeys(ctx); err != nil {
+		log.Error("failed to load signing keys from the database", "error", err)
+		_ = postgres.DBClose(db, log)
+		return err
+	}
+
+	handlers := handler.New(db, log, services, checker, cfg)
+
+	chiRouter := chi.NewRouter()
+	router.New(chiRouter, handlers, log, services)
+
+	server := &http.Server{
+		Addr:         cfg.HTTP.Address,
+		Handler:      chiRouter,
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+		IdleTimeout:  cfg.HTTP.IdleTimeout,
+	}
+
+	application := app.New(log, cfg.HTTP, server, db)
+	application.OnShutdown(checker.Drain)
+
+	application.AddWorker("refresh-token-cleanup", services.RunRefreshTokenCleanup)
+	application.AddWorker("revocation-sync", services.RunRevocationSync)
+
+	if cfg.JWT.KeyStore == config.KeyStoreDatabase {
+		application.AddWorker("signing-keys", services.RunKeyRotation)
+	}
+
+	return application.Run(ctx)
+}
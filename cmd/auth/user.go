@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"auth/internal/entity"
+	"auth/internal/http/lib/jwt"
+	"auth/internal/http/lib/schema/request"
+	"auth/internal/http/lib/validate"
+	repository "auth/internal/repository/postgres"
+	"auth/internal/service"
+	"auth/internal/storage/postgres"
+)
+
+const userUsage = `Usage: auth user <command> [flags]
+
+Commands:
+  create          create a user, --role admin bootstraps the first administrator
+  set-role        change the role of a user
+  reset-password  set a new password for a user
+  disable         disable a user so they can no longer log in
+  lock            lock a user out for a while
+  unlock          lift the lock of a user
+  list            list all users
+
+Run "auth user <command> -h" for the flags of a command.
+`
+
+type userCommand func(ctx context.Context, svc *service.Service) error
+
+func user(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprint(os.Stderr, userUsage)
+		return exitCode(2)
+	}
+
+	name, args := args[0], args[1:]
+	fs := flag.NewFlagSet("auth user "+name, flag.ExitOnError)
+
+	var cmd userCommand
+	switch name {
+	case "create":
+		cmd = userCreate(fs)
+	case "set-role":
+		cmd = userSetRole(fs)
+	case "reset-password":
+		cmd = userResetPassword(fs)
+	case "disable":
+		cmd = userDisable(fs)
+	case "lock":
+		cmd = userLock(fs)
+	case "unlock":
+		cmd = userUnlock(fs)
+	case "list":
+		cmd = userList
+	default:
+		fmt.Fprintf(os.Stderr, "unknown user command %q\n\n%s", name, userUsage)
+		return exitCode(2)
+	}
+
+	cfg, log, err := setup(fs.Name(), args, fs, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := jwt.New(cfg.JWT)
+	if err != nil {
+		log.Error("failed to load signing keys", "error", err)
+		return err
+	}
+
+	db, err := postgres.NewPool(cfg.Postgres, log)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = postgres.DBClose(db, log)
+	}()
+
+	svc := service.New(db, log, repository.New(db), tokens, cfg.JWT, cfg.OAuth)
+
+	if err = cmd(context.Background(), svc); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return err
+	}
+
+	return nil
+}
+
+func userCreate(fs *flag.FlagSet) userCommand {
+	username := fs.String("username", "", "username of the new user")
+	email := fs.String("email", "", "email of the new user")
+	role := fs.String("role", "user", "role of the new user: user, moderator or admin")
+	password := fs.String("password", "", "password, read from stdin when empty")
+
+	return func(ctx context.Context, svc *service.Service) error {
+		pass, err := readPassword(*password)
+		if err != nil {
+			return err
+		}
+
+		req := request.UserCreate{
+			Username: *username,
+			Email:    *email,
+			Password: pass,
+		}
+
+		if err = validateUser(req); err != nil {
+			return err
+		}
+
+		u := &entity.User{
+			Username:     req.Username,
+			Email:        req.Email,
+			PasswordHash: req.Password,
+			Role:         *role,
+		}
+
+		if err = svc.CreateUser(ctx, u); err != nil {
+			return describe(err)
+		}
+
+		fmt.Printf("created user %d (%s) with role %s\n", u.ID, u.Username, u.Role)
+		return nil
+	}
+}
+
+func userSetRole(fs *flag.FlagSet) userCommand {
+	ref := userFlags(fs)
+	role := fs.String("role", "", "new role: user, moderator or admin")
+
+	return func(ctx context.Context, svc *service.Service) error {
+		u, err := ref.resolve(ctx, svc)
+		if err != nil {
+			return err
+		}
+
+		if *role == "" {
+			return errors.New("--role is required")
+		}
+
+		u.Role = *role
+		if err = svc.SetUserRole(ctx, u); err != nil {
+			return describe(err)
+		}
+
+		fmt.Printf("user %d (%s) now has role %s\n", u.ID, u.Username, u.Role)
+		return nil
+	}
+}
+
+func userResetPassword(fs *flag.FlagSet) userCommand {
+	ref := userFlags(fs)
+	password := fs.String("password", "", "new password, read from stdin when empty")
+
+	return func(ctx context.Context, svc *service.Service) error {
+		u, err := ref.resolve(ctx, svc)
+		if err != nil {
+			return err
+		}
+
+		pass, err := readPassword(*password)
+		if err != nil {
+			return err
+		}
+
+		if err = validateUser(request.UserCreate{Username: u.Username, Email: u.Email, Password: pass}); err != nil {
+			return err
+		}
+
+		u.PasswordHash = pass
+		if err = svc.ResetPassword(ctx, u); err != nil {
+			return describe(err)
+		}
+
+		fmt.Printf("password reset for user %d (%s)\n", u.ID, u.Username)
+		return nil
+	}
+}
+
+func userDisable(fs *flag.FlagSet) userCommand {
+	ref := userFlags(fs)
+
+	return func(ctx context.Context, svc *service.Service) error {
+		u, err := ref.resolve(ctx, svc)
+		if err != nil {
+			return err
+		}
+
+		if err = svc.DisableUserByID(ctx, u.ID); err != nil {
+			return describe(err)
+		}
+
+		fmt.Printf("user %d (%s) disabled\n", u.ID, u.Username)
+		return nil
+	}
+}
+
+func userLock(fs *flag.FlagSet) userCommand {
+	ref := userFlags(fs)
+	duration := fs.Duration("for", time.Hour, "how long the user stays locked")
+
+	return func(ctx context.Context, svc *service.Service) error {
+		u, err := ref.resolve(ctx, svc)
+		if err != nil {
+			return err
+		}
+
+		if *duration <= 0 {
+			return errors.New("--for must be positive")
+		}
+
+		until := time.Now().Add(*duration)
+		if err = svc.LockUser(ctx, u.ID, until); err != nil {
+			return describe(err)
+		}
+
+		fmt.Printf("user %d (%s) locked until %s\n", u.ID, u.Username, until.Format(time.RFC3339))
+		return nil
+	}
+}
+
+func userUnlock(fs *flag.FlagSet) userCommand {
+	ref := userFlags(fs)
+
+	return func(ctx context.Context, svc *service.Service) error {
+		u, err := ref.resolve(ctx, svc)
+		if err != nil {
+			return err
+		}
+
+		if err = svc.LockUser(ctx, u.ID, time.Time{}); err != nil {
+			return describe(err)
+		}
+
+		fmt.Printf("user %d (%s) unlocked\n", u.ID, u.Username)
+		return nil
+	}
+}
+
+func userList(ctx context.Context, svc *service.Service) error {
+	users, err := svc.GetAllUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUSERNAME\tEMAIL\tROLE\tCREATED AT\tDISABLED AT")
+	for _, u := range users {
+		disabledAt := ""
+		if u.DisabledAt.Valid {
+			disabledAt = u.DisabledAt.Time.Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			u.ID, u.Username, u.Email, u.Role, u.CreatedAt.Format(time.RFC3339), disabledAt)
+	}
+
+	return w.Flush()
+}
+
+type userRef struct {
+	id       *int64
+	username *string
+}
+
+func userFlags(fs *flag.FlagSet) userRef {
+	return userRef{
+		id:       fs.Int64("id", 0, "ID of the user"),
+		username: fs.String("username", "", "username of the user"),
+	}
+}
+
+func (ref userRef) resolve(ctx context.Context, svc *service.Service) (*entity.User, error) {
+	u := &entity.User{ID: *ref.id, Username: *ref.username}
+
+	var err error
+	switch {
+	case u.ID != 0 && u.Username != "":
+		return nil, errors.New("use either --id or --username")
+	case u.ID != 0:
+		err = svc.GetUserByID(ctx, u)
+	case u.Username != "":
+		err = svc.GetUserByUsername(ctx, u)
+	default:
+		return nil, errors.New("--id or --username is required")
+	}
+
+	if err != nil {
+		return nil, describe(err)
+	}
+
+	return u, nil
+}
+
+func readPassword(password string) (string, error) {
+	if password != "" {
+		return password, nil
+	}
+
+	fmt.Fprint(os.Stderr, "password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("read password: %w", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func validateUser(req request.UserCreate) error {
+	v := validator.New()
+	_ = v.RegisterValidation("passwd", validate.Password)
+
+	if err := v.Struct(req); err != nil {
+		var validateErr validator.ValidationErrors
+		if errors.As(err, &validateErr) {
+			return errors.New(validate.Error(validateErr).Error)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func describe(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return errors.New("user not found")
+	case errors.Is(err, repository.DuplicateError):
+		return errors.New("username or email already exists")
+	case errors.Is(err, repository.InvalidRoleError):
+		return errors.New("role must be one of user, moderator, admin")
+	}
+
+	return err
+}
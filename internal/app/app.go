@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"auth/internal/config"
+	"auth/internal/storage/postgres"
+)
+
+// Worker is a background job that runs until its context is cancelled.
+type Worker func(ctx context.Context)
+
+type App struct {
+	log        *slog.Logger
+	cfg        config.HTTP
+	server     *http.Server
+	db         *pgxpool.Pool
+	workers    map[string]Worker
+	onShutdown []func()
+}
+
+func New(log *slog.Logger, cfg config.HTTP, server *http.Server, db *pgxpool.Pool) *App {
+	return &App{
+		log:     log,
+		cfg:     cfg,
+		server:  server,
+		db:      db,
+		workers: make(map[string]Worker),
+	}
+}
+
+func (a *App) AddWorker(name string, w Worker) {
+	a.workers[name] = w
+}
+
+// OnShutdown registers fn to run as soon as shutdown begins, before the
+// server stops accepting connections.
+func (a *App) OnShutdown(fn func()) {
+	a.onShutdown = append(a.onShutdown, fn)
+}
+
+// Run serves HTTP until ctx is cancelled or the listener fails, then drains
+// in-flight requests, stops the workers and closes the database pool.
+func (a *App) Run(ctx context.Context) error {
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var wg sync.WaitGroup
+	for name, w := range a.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.log.Info("start background worker", "worker", name)
+			w(workerCtx)
+			a.log.Info("background worker stopped", "worker", name)
+		}()
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		a.log.Info("start auth service", "address", a.server.Addr)
+		if err := a.server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+		close(serveErr)
+	}()
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		a.log.Info("shutdown signal received")
+	case err := <-serveErr:
+		a.log.Error("failed to start server", "error", err)
+		runErr = err
+	}
+
+	for _, fn := range a.onShutdown {
+		fn()
+	}
+
+	if a.cfg.DrainDelay > 0 && runErr == nil {
+		a.log.Info("waiting for load balancers to observe shutdown", "delay", a.cfg.DrainDelay)
+		time.Sleep(a.cfg.DrainDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.cfg.ShutdownTimeout)
+	defer cancel()
+
+	a.log.Info("draining http server", "timeout", a.cfg.ShutdownTimeout)
+	if err := a.server.Shutdown(shutdownCtx); err != nil {
+		a.log.Error("failed to drain http server", "error", err)
+		runErr = errors.Join(runErr, err)
+	} else {
+		a.log.Info("http server stopped")
+	}
+
+	a.log.Info("stopping background workers")
+	stopWorkers()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		a.log.Info("background workers stopped")
+	case <-shutdownCtx.Done():
+		a.log.Error("background workers did not stop in time")
+		runErr = errors.Join(runErr, shutdownCtx.Err())
+	}
+
+	if err := postgres.DBClose(a.db, a.log); err != nil {
+		runErr = errors.Join(runErr, err)
+	} else {
+		a.log.Info("success close database")
+	}
+
+	return runErr
+}
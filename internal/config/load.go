@@ -0,0 +1,233 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const configPathEnv = "AUTH_CONFIG"
+
+// Load builds the configuration from defaults, then the YAML file given by
+// --config or AUTH_CONFIG, then AUTH_* environment variables and finally the
+// command line flags registered on fs. The result is validated before return.
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	cfg := Default()
+	root := reflect.ValueOf(cfg).Elem()
+
+	path := fs.String("config", os.Getenv(configPathEnv), "path to YAML config file")
+	flags, err := bindFlags(fs, root)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *path != "" {
+		if err = loadFile(*path, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = loadEnv(root); err != nil {
+		return nil, err
+	}
+
+	for _, f := range flags {
+		if !f.set {
+			continue
+		}
+
+		if err = setValue(f.value, f.raw); err != nil {
+			return nil, fmt.Errorf("flag -%s: %w", f.name, err)
+		}
+	}
+
+	if err = cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// loadFile reads the YAML config file at path. Other formats are rejected by
+// extension rather than failing with a confusing YAML error.
+func loadFile(path string, cfg *Config) error {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+	default:
+		return fmt.Errorf("config file %s: unsupported format %q, only YAML (.yaml, .yml) is supported", path, ext)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	if err = yaml.UnmarshalStrict(data, cfg); err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func loadEnv(root reflect.Value) error {
+	return walk(root, func(field reflect.StructField, value reflect.Value) error {
+		name := field.Tag.Get("env")
+		if name == "" {
+			return nil
+		}
+
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			return nil
+		}
+
+		if err := setValue(value, raw); err != nil {
+			return fmt.Errorf("env %s: %w", name, err)
+		}
+
+		return nil
+	})
+}
+
+type flagValue struct {
+	name  string
+	value reflect.Value
+	raw   string
+	set   bool
+}
+
+func (f *flagValue) String() string {
+	if f == nil || !f.value.IsValid() {
+		return ""
+	}
+
+	if f.set {
+		return f.raw
+	}
+
+	return formatValue(f.value)
+}
+
+func (f *flagValue) Set(raw string) error {
+	if err := setValue(reflect.New(f.value.Type()).Elem(), raw); err != nil {
+		return err
+	}
+
+	f.raw = raw
+	f.set = true
+
+	return nil
+}
+
+func bindFlags(fs *flag.FlagSet, root reflect.Value) ([]*flagValue, error) {
+	var flags []*flagValue
+
+	err := walk(root, func(field reflect.StructField, value reflect.Value) error {
+		name := field.Tag.Get("flag")
+		if name == "" {
+			return nil
+		}
+
+		f := &flagValue{name: name, value: value}
+		fs.Var(f, name, field.Tag.Get("usage"))
+		flags = append(flags, f)
+
+		return nil
+	})
+
+	return flags, err
+}
+
+func walk(v reflect.Value, fn func(field reflect.StructField, value reflect.Value) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+
+		if value.Kind() == reflect.Struct {
+			if err := walk(value, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := fn(field, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setValue(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported slice type %s", v.Type())
+		}
+
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+func formatValue(v reflect.Value) string {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+
+	if v.Kind() == reflect.Slice {
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return strings.Join(items, ",")
+	}
+
+	return fmt.Sprint(v.Interface())
+}
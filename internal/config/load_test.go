@@ -0,0 +1,102 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, name, data string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadLayers(t *testing.T) {
+	path := writeConfig(t, "config.yaml", `
+env: dev
+http:
+  address: file:8080
+  read_timeout: 7s
+jwt:
+  access_secret: file-secret
+  access_ttl: 10m
+  refresh_ttl: 48h
+`)
+
+	t.Setenv(configPathEnv, path)
+	t.Setenv("AUTH_HTTP_ADDRESS", "env:8081")
+	t.Setenv("AUTH_JWT_ACCESS_TTL", "20m")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg, err := Load(fs, []string{"--jwt-access-ttl", "5m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		got, want any
+	}{
+		{"default", cfg.Log.Format, "text"},
+		{"file", cfg.Env, "dev"},
+		{"file", cfg.HTTP.ReadTimeout, 7 * time.Second},
+		{"file", cfg.JWT.RefreshTTL, 48 * time.Hour},
+		{"file", cfg.JWT.AccessSecret, "file-secret"},
+		{"env over file", cfg.HTTP.Address, "env:8081"},
+		{"flag over env and file", cfg.JWT.AccessTTL, 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigFlagOverridesEnvPath(t *testing.T) {
+	t.Setenv("AUTH_JWT_ACCESS_SECRET", "secret")
+	t.Setenv(configPathEnv, writeConfig(t, "env.yaml", "env: from-env-path\n"))
+	path := writeConfig(t, "flag.yaml", "env: from-flag-path\n")
+
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"--config", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Env != "from-flag-path" {
+		t.Errorf("env: got %q, want %q", cfg.Env, "from-flag-path")
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+		want string
+	}{
+		{"unknown field", "config.yaml", "http:\n  adress: x\n", "field adress not found"},
+		{"other format", "config.toml", "env = \"dev\"\n", "unsupported format"},
+		{"invalid value", "config.yml", "log:\n  format: xml\n", "log.format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, tt.file, tt.data)
+
+			_, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"--config", path})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// AuthorizationCode is issued by the authorization endpoint and exchanged
+// once for tokens. Only the SHA-256 of the code is stored. CodeChallenge is
+// the PKCE S256 challenge the exchange must answer. Nonce and AuthTime go
+// into the ID token when the openid scope was requested. UsedAt is set by
+// the exchange and SessionID names the session it started.
+type AuthorizationCode struct {
+	CodeHash      string       `json:"-"`
+	ClientID      string       `json:"client_id"`
+	UserID        int64        `json:"user_id"`
+	RedirectURI   string       `json:"redirect_uri"`
+	Scope         []string     `json:"scope"`
+	CodeChallenge string       `json:"code_challenge"`
+	Nonce         string       `json:"nonce"`
+	AuthTime      time.Time    `json:"auth_time"`
+	ExpiresAt     time.Time    `json:"expires_at"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UsedAt        sql.NullTime `json:"used_at"`
+	SessionID     string       `json:"session_id"`
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Grants an OAuth client may be registered for.
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantClientCredentials = "client_credentials"
+)
+
+// GrantTypes lists every grant a client may be registered for.
+var GrantTypes = []string{GrantAuthorizationCode, GrantClientCredentials}
+
+// OAuthClient is an application allowed to obtain tokens through its
+// GrantTypes: the client credentials grant for itself, or the authorization
+// code grant for users, redirecting to one of RedirectURIs. Only the SHA-256
+// of its secret is stored; public clients have none. AccessTTL and
+// RefreshTTL shorten the configured token lifetimes when not zero.
+type OAuthClient struct {
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	SecretHash   string        `json:"-"`
+	Scopes       []string      `json:"scopes"`
+	RedirectURIs []string      `json:"redirect_uris"`
+	GrantTypes   []string      `json:"grant_types"`
+	Public       bool          `json:"public"`
+	AccessTTL    time.Duration `json:"access_ttl"`
+	RefreshTTL   time.Duration `json:"refresh_ttl"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+	DisabledAt   sql.NullTime  `json:"disabled_at"`
+}
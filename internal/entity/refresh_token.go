@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RefreshToken is the server-side record of an issued refresh token. Only
+// the SHA-256 of the opaque token is stored. Tokens rotated from the same
+// login share a FamilyID. TokenVersion is the token version of the user when
+// the family was started. Scope holds the scopes requested at login, empty
+// when all scopes of the role were. ClientID is the label the caller gave
+// the session, OAuthClientID the registered client that authenticated when
+// it started, if any.
+type RefreshToken struct {
+	ID            string       `json:"id"`
+	TokenHash     string       `json:"-"`
+	FamilyID      string       `json:"family_id"`
+	UserID        int64        `json:"user_id"`
+	Audience      []string     `json:"audience"`
+	Scope         []string     `json:"scope"`
+	ClientID      string       `json:"client_id"`
+	OAuthClientID string       `json:"oauth_client_id"`
+	UserAgent     string       `json:"user_agent"`
+	IP            string       `json:"ip"`
+	TokenVersion  int64        `json:"token_version"`
+	ExpiresAt     time.Time    `json:"expires_at"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UsedAt        sql.NullTime `json:"used_at"`
+	RevokedAt     sql.NullTime `json:"revoked_at"`
+}
+
+// ClientInfo describes where a token request came from. Label is the
+// free-form client name the caller sent and is never trusted. ClientID is
+// only set by the service once the OAuth client authenticated.
+type ClientInfo struct {
+	Label     string
+	ClientID  string
+	UserAgent string
+	IP        string
+}
@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RevokedToken is an entry of the revocation list. It is kept until the
+// token would have expired anyway.
+type RevokedToken struct {
+	ID        string        `json:"jti"`
+	UserID    sql.NullInt64 `json:"user_id"`
+	Reason    string        `json:"reason"`
+	ExpiresAt time.Time     `json:"expires_at"`
+	RevokedAt time.Time     `json:"revoked_at"`
+}
@@ -0,0 +1,38 @@
+package entity
+
+// Scopes carried in the scope claim of access tokens. A scope limits what a
+// token may be used for; the role still decides what the user may do.
+const (
+	ScopeUsersRead    = "users:read"
+	ScopeUsersWrite   = "users:write"
+	ScopeKeysRead     = "keys:read"
+	ScopeKeysWrite    = "keys:write"
+	ScopeTokensRevoke = "tokens:revoke"
+	ScopeClientsRead  = "clients:read"
+	ScopeClientsWrite = "clients:write"
+)
+
+// OpenID Connect scopes. They grant access to claims about the user through
+// ID tokens and the userinfo endpoint rather than to API routes, so any user
+// may be granted them.
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// IdentityScopes lists the OpenID Connect scopes.
+var IdentityScopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail}
+
+// Scopes lists every scope, in the order they are documented.
+var Scopes = []string{
+	ScopeUsersRead, ScopeUsersWrite, ScopeKeysRead, ScopeKeysWrite, ScopeTokensRevoke, ScopeClientsRead, ScopeClientsWrite,
+}
+
+// RoleScopes lists the scopes each role may be granted. Tokens get all of
+// them unless fewer are requested.
+var RoleScopes = map[string][]string{
+	"user":      {ScopeUsersRead, ScopeUsersWrite},
+	"moderator": {ScopeUsersRead, ScopeUsersWrite},
+	"admin":     {ScopeUsersRead, ScopeUsersWrite, ScopeKeysRead, ScopeKeysWrite, ScopeTokensRevoke, ScopeClientsRead, ScopeClientsWrite},
+}
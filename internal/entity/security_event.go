@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	EventRefreshTokenReuse = "refresh_token_reuse"
+	EventLogoutAll         = "logout_all"
+	EventImpersonation     = "impersonation"
+
+	EventAuthorizationCodeReuse = "authorization_code_reuse"
+)
+
+type SecurityEvent struct {
+	ID        int64          `json:"id"`
+	UserID    sql.NullInt64  `json:"user_id"`
+	Kind      string         `json:"kind"`
+	Details   map[string]any `json:"details"`
+	CreatedAt time.Time      `json:"created_at"`
+}
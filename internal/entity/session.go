@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// Session is one login of a user on a device. The refresh tokens issued
+// for it form a family whose FamilyID is the session ID.
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     int64     `json:"user_id"`
+	ClientID   string    `json:"client_id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
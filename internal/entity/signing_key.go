@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SigningKey is an access token key as stored in the database key store.
+// PrivateKey holds the encrypted key material.
+type SigningKey struct {
+	ID         string       `json:"kid"`
+	Algorithm  string       `json:"algorithm"`
+	PrivateKey []byte       `json:"-"`
+	State      string       `json:"state"`
+	CreatedAt  time.Time    `json:"created_at"`
+	RotatedAt  sql.NullTime `json:"rotated_at"`
+	RetiredAt  sql.NullTime `json:"retired_at"`
+}
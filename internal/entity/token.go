@@ -1,14 +1,111 @@
 package entity
 
-import "github.com/golang-jwt/jwt/v5"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Values of the token_use claim. Client tokens are access tokens issued to
+// an OAuth client rather than a user.
+const (
+	TokenUseAccess = "access"
+	TokenUseClient = "client"
+)
 
 type Token struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Scope        string    `json:"scope,omitempty"`
+	IDToken      string    `json:"id_token,omitempty"`
+	SessionID    string    `json:"-"`
+}
+
+// Actor is the party acting on behalf of the subject of a token, as in the
+// act claim of RFC 8693.
+type Actor struct {
+	Sub int64 `json:"sub"`
+}
+
+// AccessClaims authorize API requests. TokenUse is TokenUseAccess or
+// TokenUseClient, Scope is a space-separated list of granted scopes, Ver is
+// the token version of the user at issue time and Sid the session the token
+// was issued for. Act is set on impersonation tokens and names the admin
+// using them. Client tokens carry the client ID in sub instead of a user ID
+// and leave Sub zero.
+type AccessClaims struct {
+	Sub      int64  `json:"sub"`
+	ClientID string `json:"-"`
+	Role     string `json:"role"`
+	Scope    string `json:"scope"`
+	Ver      int64  `json:"ver"`
+	Sid      string `json:"sid,omitempty"`
+	Act      *Actor `json:"act,omitempty"`
+	TokenUse string `json:"token_use"`
+	jwt.RegisteredClaims
+}
+
+// accessClaims has the fields of AccessClaims without its JSON methods.
+type accessClaims AccessClaims
+
+// MarshalJSON writes the client ID as sub for client tokens.
+func (c AccessClaims) MarshalJSON() ([]byte, error) {
+	if c.TokenUse != TokenUseClient {
+		return json.Marshal(accessClaims(c))
+	}
+
+	return json.Marshal(struct {
+		accessClaims
+		Sub string `json:"sub"`
+	}{accessClaims(c), c.ClientID})
+}
+
+// UnmarshalJSON reads sub as a user ID, or as the client ID for client
+// tokens.
+func (c *AccessClaims) UnmarshalJSON(b []byte) error {
+	aux := struct {
+		*accessClaims
+		Sub json.RawMessage `json:"sub"`
+	}{accessClaims: (*accessClaims)(c)}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	if len(aux.Sub) == 0 {
+		return nil
+	}
+
+	if c.TokenUse == TokenUseClient {
+		return json.Unmarshal(aux.Sub, &c.ClientID)
+	}
+
+	return json.Unmarshal(aux.Sub, &c.Sub)
+}
+
+// Profile holds the standard OpenID Connect claims about a user that this
+// service knows. Which of them are set depends on the granted scopes.
+type Profile struct {
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	UpdatedAt         int64  `json:"updated_at,omitempty"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     *bool  `json:"email_verified,omitempty"`
+}
+
+// UserInfo is the response of the userinfo endpoint. Sub is the user ID.
+type UserInfo struct {
+	Sub string `json:"sub"`
+	Profile
 }
 
-type Claims struct {
-	Sub  int64  `json:"sub"`
-	Role string `json:"role"`
+// IDClaims are the claims of an OpenID Connect ID token, issued to the
+// client named by the audience. Nonce echoes the authorization request and
+// AuthTime is when the user signed in.
+type IDClaims struct {
+	Nonce    string           `json:"nonce,omitempty"`
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+	Profile
 	jwt.RegisteredClaims
 }
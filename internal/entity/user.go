@@ -14,4 +14,7 @@ type User struct {
 	Role         string        `json:"role"`
 	CreatedAt    time.Time     `json:"created_at"`
 	UpdatedAt    time.Time     `json:"updated_at"`
+	DisabledAt   sql.NullTime  `json:"disabled_at"`
+	LockedUntil  sql.NullTime  `json:"locked_until"`
+	TokenVersion int64         `json:"token_version"`
 }
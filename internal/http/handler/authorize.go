@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"auth/internal/entity"
+	"auth/internal/service"
+	"auth/package/utils"
+)
+
+type AuthorizationService interface {
+	ValidateAuthorization(ctx context.Context, c *entity.AuthorizationCode, scope string) error
+	Authorize(ctx context.Context, u *entity.User, c *entity.AuthorizationCode, scope string) (string, error)
+	ExchangeCode(ctx context.Context, code, clientID, secret, redirectURI, verifier string, client entity.ClientInfo) (*entity.Token, error)
+}
+
+// authorizePage is the sign-in form of the authorization endpoint. The
+// request parameters travel in hidden fields, so the POST carries the same
+// request the GET validated.
+var authorizePage = template.Must(template.New("authorize").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Sign in</title>
+<style>
+body { font-family: sans-serif; max-width: 22rem; margin: 4rem auto; padding: 0 1rem; }
+label, input, button { display: block; width: 100%; box-sizing: border-box; margin-top: .5rem; }
+.error { color: #b00020; }
+</style>
+</head>
+<body>
+{{if .Fatal}}
+<h1>Authorization failed</h1>
+<p class="error">{{.Error}}</p>
+{{else}}
+<h1>Sign in</h1>
+<p><strong>{{.ClientID}}</strong> requests access to: {{.Scope}}</p>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<form method="post" action="">
+{{range $name, $value := .Params}}<input type="hidden" name="{{$name}}" value="{{$value}}">
+{{end}}<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+<label for="username">Username</label>
+<input id="username" name="username" autocomplete="username" required autofocus>
+<label for="password">Password</label>
+<input id="password" name="password" type="password" autocomplete="current-password" required>
+<button type="submit">Sign in</button>
+</form>
+{{end}}
+</body>
+</html>
+`))
+
+type authorizeView struct {
+	Fatal     bool
+	Error     string
+	ClientID  string
+	Scope     string
+	Params    map[string]string
+	CSRFToken string
+}
+
+// csrfCookie holds the anti-CSRF token of the sign-in form. The form must
+// post the same value back; SameSite keeps other sites from sending the
+// cookie along with a forged form.
+const (
+	csrfCookie = "oauth_csrf"
+	csrfMaxAge = 10 * 60
+)
+
+// authorizeParams are the request parameters echoed by the sign-in form.
+var authorizeParams = []string{
+	"response_type", "client_id", "redirect_uri", "scope", "state", "nonce", "code_challenge", "code_challenge_method",
+}
+
+// Authorize godoc
+// @Summary      OAuth authorization endpoint
+// @Description  Starts the authorization code flow (RFC 6749 section 4.1) with PKCE (RFC 7636). GET validates the request and renders a sign-in page, which posts the credentials back along with the request and an anti-CSRF token bound to a cookie.
+// @Description  redirect_uri must be registered for the client and code_challenge must be an S256 challenge. Errors about the client or redirect_uri are shown on the page, others are sent to redirect_uri with error and state. On success the user agent is redirected with code, state and iss; the code is valid for a minute and is exchanged at /oauth/token
+// @Tags         oauth
+// @Produce      html
+// @Param        response_type          query  string  true   "code"
+// @Param        client_id              query  string  true   "Client ID"
+// @Param        redirect_uri           query  string  true   "Registered redirect URI"
+// @Param        scope                  query  string  false  "Space-separated scopes, all scopes of the client when empty; openid adds an ID token"
+// @Param        state                  query  string  false  "Opaque value returned with the redirect"
+// @Param        nonce                  query  string  false  "Value echoed in the ID token"
+// @Param        code_challenge         query  string  true   "PKCE code challenge"
+// @Param        code_challenge_method  query  string  true   "S256"
+// @Success      200  {string}  string  "Sign-in page"
+// @Success      302  {string}  string  "Redirect to redirect_uri"
+// @Failure      400  {string}  string  "Error page"
+// @Router       /oauth/authorize [get]
+// @Router       /oauth/authorize [post]
+func (h *Handler) Authorize() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; form-action 'self'; frame-ancestors 'none'")
+
+		if err := r.ParseForm(); err != nil {
+			renderAuthorizePage(w, http.StatusBadRequest, authorizeView{Fatal: true, Error: "malformed request"})
+			return
+		}
+
+		params := make(map[string]string, len(authorizeParams))
+		for _, name := range authorizeParams {
+			params[name] = r.Form.Get(name)
+		}
+
+		c := &entity.AuthorizationCode{
+			ClientID:      params["client_id"],
+			RedirectURI:   params["redirect_uri"],
+			CodeChallenge: params["code_challenge"],
+			Nonce:         params["nonce"],
+		}
+
+		err := h.svc.ValidateAuthorization(r.Context(), c, params["scope"])
+		switch {
+		case errors.Is(err, service.InvalidClientError), errors.Is(err, service.InvalidRedirectURIError):
+			renderAuthorizePage(w, http.StatusBadRequest, authorizeView{Fatal: true, Error: err.Error()})
+			return
+		case errors.Is(err, service.UnauthorizedClientError):
+			h.authorizeRedirect(w, r, c.RedirectURI, params["state"], url.Values{"error": {"unauthorized_client"}})
+			return
+		case params["response_type"] != "code":
+			h.authorizeRedirect(w, r, c.RedirectURI, params["state"], url.Values{"error": {"unsupported_response_type"}})
+			return
+		case errors.Is(err, service.InvalidScopeError):
+			h.authorizeRedirect(w, r, c.RedirectURI, params["state"], url.Values{"error": {"invalid_scope"}})
+			return
+		case errors.Is(err, service.InvalidCodeChallengeError), params["code_challenge_method"] != "S256":
+			h.authorizeRedirect(w, r, c.RedirectURI, params["state"], url.Values{
+				"error":             {"invalid_request"},
+				"error_description": {service.InvalidCodeChallengeError.Error()},
+			})
+			return
+		case err != nil:
+			h.authorizeRedirect(w, r, c.RedirectURI, params["state"], url.Values{"error": {"server_error"}})
+			return
+		}
+
+		view := authorizeView{ClientID: c.ClientID, Scope: params["scope"], Params: params}
+		if view.Scope == "" {
+			view.Scope = "all scopes of the client"
+		}
+
+		if r.Method != http.MethodPost {
+			h.renderSignIn(w, r, http.StatusOK, view)
+			return
+		}
+
+		if !validCSRF(r) {
+			view.Error = "the sign-in form expired, please sign in again"
+			h.renderSignIn(w, r, http.StatusForbidden, view)
+			return
+		}
+
+		u := &entity.User{
+			Username:     r.PostForm.Get("username"),
+			PasswordHash: r.PostForm.Get("password"),
+		}
+
+		code, err := h.svc.Authorize(r.Context(), u, c, params["scope"])
+		switch {
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+			view.Error = "invalid username or password"
+			h.renderSignIn(w, r, http.StatusUnauthorized, view)
+			return
+		case errors.Is(err, service.UserDisabledError), errors.Is(err, service.UserLockedError):
+			view.Error = err.Error()
+			h.renderSignIn(w, r, http.StatusForbidden, view)
+			return
+		case err != nil:
+			h.authorizeRedirect(w, r, c.RedirectURI, params["state"], url.Values{"error": {"server_error"}})
+			return
+		}
+
+		h.authorizeRedirect(w, r, c.RedirectURI, params["state"], url.Values{"code": {code}})
+	}
+}
+
+// authorizeRedirect sends the user agent back to the client with values,
+// state and the issuer (RFC 9207).
+func (h *Handler) authorizeRedirect(w http.ResponseWriter, r *http.Request, redirectURI, state string, values url.Values) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		renderAuthorizePage(w, http.StatusBadRequest, authorizeView{Fatal: true, Error: "invalid redirect_uri"})
+		return
+	}
+
+	q := u.Query()
+	for k, v := range values {
+		q[k] = v
+	}
+
+	if state != "" {
+		q.Set("state", state)
+	}
+
+	q.Set("iss", h.cfg.JWT.Issuer)
+	u.RawQuery = q.Encode()
+
+	http.Redirect(w, r, u.String(), http.StatusFound)
+}
+
+// renderSignIn renders the sign-in form with a fresh anti-CSRF token, set as
+// a cookie as well.
+func (h *Handler) renderSignIn(w http.ResponseWriter, r *http.Request, status int, view authorizeView) {
+	token, err := utils.RandomToken()
+	if err != nil {
+		renderAuthorizePage(w, http.StatusInternalServerError, authorizeView{Fatal: true, Error: "internal error"})
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookie,
+		Value:    token,
+		Path:     r.URL.Path,
+		MaxAge:   csrfMaxAge,
+		HttpOnly: true,
+		Secure:   r.TLS != nil || strings.HasPrefix(h.cfg.JWT.Issuer, "https://"),
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	view.CSRFToken = token
+	renderAuthorizePage(w, status, view)
+}
+
+// validCSRF reports whether the posted form carries the anti-CSRF token of
+// its cookie.
+func validCSRF(r *http.Request) bool {
+	cookie, err := r.Cookie(csrfCookie)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(r.PostForm.Get("csrf_token"))) == 1
+}
+
+func renderAuthorizePage(w http.ResponseWriter, status int, view authorizeView) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_ = authorizePage.Execute(w, view)
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net"
+	"net/http"
+	"unicode/utf8"
+
+	"auth/internal/entity"
+)
+
+const maxUserAgent = 512
+
+// clientInfo describes the caller of r for the refresh token record. label
+// is the client name the caller sent, if any.
+func clientInfo(r *http.Request, label string) entity.ClientInfo {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	ua := r.UserAgent()
+	if len(ua) > maxUserAgent {
+		// Cut on a character boundary, Postgres rejects invalid UTF-8.
+		n := maxUserAgent
+		for n > 0 && !utf8.RuneStart(ua[n]) {
+			n--
+		}
+		ua = ua[:n]
+	}
+
+	return entity.ClientInfo{Label: label, UserAgent: ua, IP: ip}
+}
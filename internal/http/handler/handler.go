@@ -4,19 +4,29 @@ import (
 	"log/slog"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"auth/internal/config"
+	"auth/internal/health"
 )
 
 type Handler struct {
-	db  *pgxpool.Pool
-	log *slog.Logger
-	svc Service
+	db     *pgxpool.Pool
+	log    *slog.Logger
+	svc    Service
+	health *health.Checker
+	cfg    *config.Config
 }
 
 type Service interface {
 	UserService
 	TokenService
+	KeyService
+	SessionService
+	AuthorizationService
+	OIDCService
+	ClientService
 }
 
-func New(db *pgxpool.Pool, log *slog.Logger, svc Service) *Handler {
-	return &Handler{db, log, svc}
+func New(db *pgxpool.Pool, log *slog.Logger, svc Service, health *health.Checker, cfg *config.Config) *Handler {
+	return &Handler{db, log, svc, health, cfg}
 }
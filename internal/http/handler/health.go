@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+
+	"auth/internal/health"
+	"auth/internal/http/lib/schema/response"
+)
+
+// Liveness godoc
+// @Summary      Liveness probe
+// @Description  Reports that the process is running and able to serve requests
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  response.Health
+// @Router       /healthz [get]
+func (h *Handler) Liveness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, response.Health{Status: health.StatusOK})
+	}
+}
+
+// Readiness godoc
+// @Summary      Readiness probe
+// @Description  Checks the database, schema version and signing keys
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  response.Health
+// @Failure      503  {object}  response.Health
+// @Router       /readyz [get]
+func (h *Handler) Readiness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := h.health.Check(r.Context())
+
+		resp := response.Health{
+			Status: report.Status,
+			Checks: make(map[string]response.HealthCheck, len(report.Checks)),
+		}
+
+		for name, res := range report.Checks {
+			resp.Checks[name] = response.HealthCheck{
+				Status:    res.Status,
+				LatencyMS: float64(res.Latency) / float64(time.Millisecond),
+				Error:     res.Error,
+			}
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		if report.Status != health.StatusOK {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+
+		render.JSON(w, r, resp)
+	}
+}
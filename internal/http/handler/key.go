@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/render"
+
+	"auth/internal/entity"
+	"auth/internal/http/lib/permission"
+	"auth/internal/http/lib/schema/response"
+	"auth/internal/service"
+)
+
+type KeyService interface {
+	JWKS() []entity.JWK
+	ListKeys(ctx context.Context) ([]*entity.SigningKey, error)
+	RotateKey(ctx context.Context, retirePrevious bool) (*entity.SigningKey, error)
+}
+
+// JWKS godoc
+// @Summary      JSON Web Key Set
+// @Description  Public keys that verify access tokens, selected by the kid header
+// @Tags         keys
+// @Produce      json
+// @Success      200  {object}  response.JWKS
+// @Router       /.well-known/jwks.json [get]
+func (h *Handler) JWKS() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.cfg.JWT.JWKSMaxAge.Seconds())))
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, response.JWKS{Keys: h.svc.JWKS()})
+	}
+}
+
+// ListKeys godoc
+// @Summary      List signing keys
+// @Description  Access token signing keys with their state, newest first. Admins and OAuth clients with the scope only
+// @Tags         keys
+// @Produce      json
+// @Success      200  {array}   response.SigningKey
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /admin/keys [get]
+// @Security     BearerAuth
+func (h *Handler) ListKeys() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !(permission.Admin(r.Context()) || permission.ClientScope(r.Context(), entity.ScopeKeysRead)) {
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, response.Error("forbidden"))
+			return
+		}
+
+		keys, err := h.svc.ListKeys(r.Context())
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to list signing keys"))
+			return
+		}
+
+		resp := make([]response.SigningKey, 0, len(keys))
+		for _, k := range keys {
+			resp = append(resp, response.NewSigningKey(k))
+		}
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, resp)
+	}
+}
+
+// RotateKey godoc
+// @Summary      Rotate the signing key
+// @Description  Generates a new active key. The previous key keeps verifying outstanding tokens until they expire unless retire_previous is true. Admins and OAuth clients with the scope only
+// @Tags         keys
+// @Produce      json
+// @Param        retire_previous  query     bool  false  "Reject tokens signed by the previous key right away"
+// @Success      201  {object}  response.SigningKey
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /admin/keys/rotate [post]
+// @Security     BearerAuth
+func (h *Handler) RotateKey() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !(permission.Admin(r.Context()) || permission.ClientScope(r.Context(), entity.ScopeKeysWrite)) {
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, response.Error("forbidden"))
+			return
+		}
+
+		retire, err := strconv.ParseBool(r.URL.Query().Get("retire_previous"))
+		if err != nil && r.URL.Query().Has("retire_previous") {
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, response.Error("retire_previous must be a boolean"))
+			return
+		}
+
+		k, err := h.svc.RotateKey(r.Context(), retire)
+		if errors.Is(err, service.KeyStoreReadOnlyError) || errors.Is(err, service.RotationConflictError) {
+			w.WriteHeader(http.StatusConflict)
+			render.JSON(w, r, response.Error(err.Error()))
+			return
+		}
+
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to rotate signing key"))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		render.JSON(w, r, response.NewSigningKey(k))
+	}
+}
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/render"
+
+	"auth/internal/entity"
+	"auth/internal/http/lib/schema/response"
+	"auth/internal/service"
+)
+
+// Grant and token types of the token endpoint.
+const (
+	grantAuthorizationCode = entity.GrantAuthorizationCode
+	grantClientCredentials = entity.GrantClientCredentials
+	grantTokenExchange     = "urn:ietf:params:oauth:grant-type:token-exchange"
+	tokenTypeAccessToken   = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// Token godoc
+// @Summary      OAuth token endpoint
+// @Description  Issues tokens for the supported grants.
+// @Description  The authorization code grant exchanges a code from /oauth/authorize for an access and refresh token of the user, plus an ID token when the openid scope was granted. code_verifier must answer the PKCE challenge and redirect_uri must be the one of the authorization request. Confidential clients authenticate as for the client credentials grant, public clients send only client_id.
+// @Description  The client credentials grant issues a token to an OAuth client, which authenticates with HTTP Basic or client_id and client_secret. The token carries the client ID as sub and only the scopes of the client.
+// @Description  The token exchange grant (RFC 8693) lets an admin impersonate a user: subject_token is the admin's access token and requested_subject the ID of the user. The token carries an act claim, cannot be refreshed and is blocked from sensitive actions
+// @Tags         oauth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        grant_type          formData  string  true   "authorization_code, client_credentials or urn:ietf:params:oauth:grant-type:token-exchange"
+// @Param        code                formData  string  false  "Authorization code"
+// @Param        redirect_uri        formData  string  false  "Redirect URI of the authorization request"
+// @Param        code_verifier       formData  string  false  "PKCE code verifier"
+// @Param        client_id           formData  string  false  "Client ID, unless sent with HTTP Basic"
+// @Param        client_secret       formData  string  false  "Client secret, unless sent with HTTP Basic"
+// @Param        subject_token       formData  string  false  "Access token of the admin"
+// @Param        subject_token_type  formData  string  false  "urn:ietf:params:oauth:token-type:access_token"
+// @Param        requested_subject   formData  string  false  "ID of the user to impersonate"
+// @Param        audience            formData  string  false  "Audience of the issued token"
+// @Param        scope               formData  string  false  "Space-separated scopes of the issued token"
+// @Success      200  {object}  response.OAuthToken
+// @Failure      400  {object}  response.OAuthError
+// @Failure      401  {object}  response.OAuthError
+// @Failure      403  {object}  response.OAuthError
+// @Failure      500  {object}  response.OAuthError
+// @Router       /oauth/token [post]
+func (h *Handler) Token() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+
+		if err := r.ParseForm(); err != nil {
+			oauthError(w, r, http.StatusBadRequest, "invalid_request", "malformed form body")
+			return
+		}
+
+		switch grant := r.PostForm.Get("grant_type"); grant {
+		case grantAuthorizationCode:
+			h.authorizationCode(w, r)
+		case grantClientCredentials:
+			h.clientCredentials(w, r)
+		case grantTokenExchange:
+			h.tokenExchange(w, r)
+		case "":
+			oauthError(w, r, http.StatusBadRequest, "invalid_request", "grant_type is required")
+		default:
+			oauthError(w, r, http.StatusBadRequest, "unsupported_grant_type", "grant_type "+grant+" is not supported")
+		}
+	}
+}
+
+func (h *Handler) authorizationCode(w http.ResponseWriter, r *http.Request) {
+	form := r.PostForm
+	clientID, secret, basic := clientCredentials(r)
+
+	if form.Get("code") == "" || form.Get("redirect_uri") == "" || form.Get("code_verifier") == "" || clientID == "" {
+		oauthError(w, r, http.StatusBadRequest, "invalid_request", "code, redirect_uri, code_verifier and client_id are required")
+		return
+	}
+
+	token, err := h.svc.ExchangeCode(r.Context(), form.Get("code"), clientID, secret,
+		form.Get("redirect_uri"), form.Get("code_verifier"), clientInfo(r, ""))
+	switch {
+	case errors.Is(err, service.InvalidClientError):
+		if basic {
+			w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		oauthError(w, r, http.StatusUnauthorized, "invalid_client", err.Error())
+		return
+	case errors.Is(err, service.InvalidGrantError):
+		oauthError(w, r, http.StatusBadRequest, "invalid_grant", err.Error())
+		return
+	case errors.Is(err, service.UnauthorizedClientError):
+		oauthError(w, r, http.StatusBadRequest, "unauthorized_client", err.Error())
+		return
+	case err != nil:
+		oauthError(w, r, http.StatusInternalServerError, "server_error", "failed to issue token")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, oauthToken(token, ""))
+}
+
+func (h *Handler) clientCredentials(w http.ResponseWriter, r *http.Request) {
+	clientID, secret, basic := clientCredentials(r)
+
+	if clientID == "" || secret == "" {
+		oauthError(w, r, http.StatusBadRequest, "invalid_request", "client credentials are required")
+		return
+	}
+
+	token, err := h.svc.ClientCredentials(r.Context(), clientID, secret, r.PostForm.Get("audience"), r.PostForm.Get("scope"))
+	switch {
+	case errors.Is(err, service.InvalidClientError):
+		if basic {
+			w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		oauthError(w, r, http.StatusUnauthorized, "invalid_client", err.Error())
+		return
+	case errors.Is(err, service.UnauthorizedClientError):
+		oauthError(w, r, http.StatusBadRequest, "unauthorized_client", err.Error())
+		return
+	case errors.Is(err, service.InvalidAudienceError):
+		oauthError(w, r, http.StatusBadRequest, "invalid_target", err.Error())
+		return
+	case errors.Is(err, service.InvalidScopeError):
+		oauthError(w, r, http.StatusBadRequest, "invalid_scope", err.Error())
+		return
+	case err != nil:
+		oauthError(w, r, http.StatusInternalServerError, "server_error", "failed to issue token")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, oauthToken(token, ""))
+}
+
+func (h *Handler) tokenExchange(w http.ResponseWriter, r *http.Request) {
+	form := r.PostForm
+
+	if form.Get("subject_token") == "" || form.Get("subject_token_type") != tokenTypeAccessToken {
+		oauthError(w, r, http.StatusBadRequest, "invalid_request", "subject_token must be an access token")
+		return
+	}
+
+	if t := form.Get("requested_token_type"); t != "" && t != tokenTypeAccessToken {
+		oauthError(w, r, http.StatusBadRequest, "invalid_request", "only access tokens can be requested")
+		return
+	}
+
+	targetID, err := strconv.ParseInt(form.Get("requested_subject"), 10, 64)
+	if err != nil || targetID <= 0 {
+		oauthError(w, r, http.StatusBadRequest, "invalid_request", "requested_subject must be a user ID")
+		return
+	}
+
+	token, err := h.svc.Impersonate(r.Context(), form.Get("subject_token"), targetID,
+		form.Get("audience"), form.Get("scope"), clientInfo(r, ""))
+	switch {
+	case errors.Is(err, service.InvalidTokenError):
+		oauthError(w, r, http.StatusBadRequest, "invalid_grant", "subject_token is invalid")
+		return
+	case errors.Is(err, service.ImpersonationForbiddenError):
+		oauthError(w, r, http.StatusForbidden, "access_denied", err.Error())
+		return
+	case errors.Is(err, sql.ErrNoRows):
+		oauthError(w, r, http.StatusBadRequest, "invalid_request", "user not found")
+		return
+	case errors.Is(err, service.ImpersonationTargetError),
+		errors.Is(err, service.UserDisabledError),
+		errors.Is(err, service.UserLockedError):
+		oauthError(w, r, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	case errors.Is(err, service.InvalidAudienceError):
+		oauthError(w, r, http.StatusBadRequest, "invalid_target", err.Error())
+		return
+	case errors.Is(err, service.InvalidScopeError):
+		oauthError(w, r, http.StatusBadRequest, "invalid_scope", err.Error())
+		return
+	case err != nil:
+		oauthError(w, r, http.StatusInternalServerError, "server_error", "failed to issue token")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, oauthToken(token, tokenTypeAccessToken))
+}
+
+// clientCredentials returns the client credentials of r, sent with HTTP
+// Basic or in the form, and whether Basic was used.
+func clientCredentials(r *http.Request) (clientID, secret string, basic bool) {
+	clientID, secret, basic = r.BasicAuth()
+	if !basic {
+		return r.PostForm.Get("client_id"), r.PostForm.Get("client_secret"), false
+	}
+
+	// RFC 6749 section 2.3.1 form-encodes the credentials before Basic.
+	clientID, _ = url.QueryUnescape(clientID)
+	secret, _ = url.QueryUnescape(secret)
+	return clientID, secret, true
+}
+
+func oauthToken(t *entity.Token, issuedType string) response.OAuthToken {
+	return response.OAuthToken{
+		AccessToken:     t.AccessToken,
+		IssuedTokenType: issuedType,
+		TokenType:       "Bearer",
+		ExpiresIn:       int64(time.Until(t.ExpiresAt).Seconds()),
+		RefreshToken:    t.RefreshToken,
+		Scope:           t.Scope,
+		IDToken:         t.IDToken,
+	}
+}
+
+func oauthError(w http.ResponseWriter, r *http.Request, status int, code, description string) {
+	w.WriteHeader(status)
+	render.JSON(w, r, response.OAuthError{Error: code, ErrorDescription: description})
+}
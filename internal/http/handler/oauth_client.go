@@ -0,0 +1,314 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	"auth/internal/entity"
+	"auth/internal/http/lib/permission"
+	"auth/internal/http/lib/schema/request"
+	"auth/internal/http/lib/schema/response"
+	"auth/internal/http/lib/validate"
+	"auth/internal/service"
+)
+
+type ClientService interface {
+	CreateClient(ctx context.Context, c *entity.OAuthClient) (string, error)
+	RegisterClient(ctx context.Context, initialToken string, c *entity.OAuthClient) (string, error)
+	GetClient(ctx context.Context, id string) (*entity.OAuthClient, error)
+	ListClients(ctx context.Context) ([]*entity.OAuthClient, error)
+	UpdateClient(ctx context.Context, c *entity.OAuthClient) error
+	RotateClientSecret(ctx context.Context, id string) (string, error)
+	DeleteClient(ctx context.Context, id string) error
+}
+
+// ListClients godoc
+// @Summary      List OAuth clients
+// @Description  Registered OAuth clients, oldest first. Admins only
+// @Tags         clients
+// @Produce      json
+// @Success      200  {array}   response.OAuthClient
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /admin/clients [get]
+// @Security     BearerAuth
+func (h *Handler) ListClients() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !permission.Admin(r.Context()) {
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, response.Error("forbidden"))
+			return
+		}
+
+		clients, err := h.svc.ListClients(r.Context())
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to list clients"))
+			return
+		}
+
+		resp := make([]response.OAuthClient, 0, len(clients))
+		for _, c := range clients {
+			resp = append(resp, response.NewOAuthClient(c))
+		}
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, resp)
+	}
+}
+
+// GetClient godoc
+// @Summary      Get an OAuth client
+// @Description  Admins only
+// @Tags         clients
+// @Produce      json
+// @Param        id   path      string  true  "Client ID"
+// @Success      200  {object}  response.OAuthClient
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /admin/clients/{id} [get]
+// @Security     BearerAuth
+func (h *Handler) GetClient() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !permission.Admin(r.Context()) {
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, response.Error("forbidden"))
+			return
+		}
+
+		c, err := h.svc.GetClient(r.Context(), chi.URLParam(r, "id"))
+		if errors.Is(err, sql.ErrNoRows) {
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, response.Error("client not found"))
+			return
+		}
+
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to get client"))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, response.NewOAuthClient(c))
+	}
+}
+
+// CreateClient godoc
+// @Summary      Create an OAuth client
+// @Description  Registers a client and returns its secret, which cannot be shown again. Public clients get no secret and can only use the authorization code grant. Redirect URIs must use https, or http on a loopback host. Without grant_types the client gets authorization_code when it has redirect URIs and client_credentials unless it is public. access_ttl and refresh_ttl shorten the token lifetimes in seconds and cannot exceed the configured ones. Admins only
+// @Tags         clients
+// @Accept       json
+// @Produce      json
+// @Param        client  body      request.ClientCreate  true  "Client settings"
+// @Success      201     {object}  response.OAuthClientSecret
+// @Failure      400     {object}  response.Response
+// @Failure      401     {object}  response.Response
+// @Failure      403     {object}  response.Response
+// @Failure      500     {object}  response.Response
+// @Router       /admin/clients [post]
+// @Security     BearerAuth
+func (h *Handler) CreateClient() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !permission.Admin(r.Context()) {
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, response.Error("forbidden"))
+			return
+		}
+
+		var req request.ClientCreate
+		if !decodeClientRequest(w, r, &req) {
+			return
+		}
+
+		c := &entity.OAuthClient{
+			Name:         req.Name,
+			Public:       req.Public,
+			Scopes:       req.Scopes,
+			RedirectURIs: req.RedirectURIs,
+			GrantTypes:   req.GrantTypes,
+			AccessTTL:    time.Duration(req.AccessTTL) * time.Second,
+			RefreshTTL:   time.Duration(req.RefreshTTL) * time.Second,
+		}
+
+		secret, err := h.svc.CreateClient(r.Context(), c)
+		if clientError(w, r, err, "failed to create client") {
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		render.JSON(w, r, response.OAuthClientSecret{OAuthClient: response.NewOAuthClient(c), Secret: secret})
+	}
+}
+
+// UpdateClient godoc
+// @Summary      Update an OAuth client
+// @Description  Replaces the settings of a client. Disabled clients cannot get tokens; whether a client is public cannot change. Admins only
+// @Tags         clients
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string                true  "Client ID"
+// @Param        client  body      request.ClientUpdate  true  "Client settings"
+// @Success      200     {object}  response.OAuthClient
+// @Failure      400     {object}  response.Response
+// @Failure      401     {object}  response.Response
+// @Failure      403     {object}  response.Response
+// @Failure      404     {object}  response.Response
+// @Failure      500     {object}  response.Response
+// @Router       /admin/clients/{id} [put]
+// @Security     BearerAuth
+func (h *Handler) UpdateClient() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !permission.Admin(r.Context()) {
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, response.Error("forbidden"))
+			return
+		}
+
+		var req request.ClientUpdate
+		if !decodeClientRequest(w, r, &req) {
+			return
+		}
+
+		c := &entity.OAuthClient{
+			ID:           chi.URLParam(r, "id"),
+			Name:         req.Name,
+			Scopes:       req.Scopes,
+			RedirectURIs: req.RedirectURIs,
+			GrantTypes:   req.GrantTypes,
+			AccessTTL:    time.Duration(req.AccessTTL) * time.Second,
+			RefreshTTL:   time.Duration(req.RefreshTTL) * time.Second,
+			DisabledAt:   sql.NullTime{Time: time.Now(), Valid: req.Disabled},
+		}
+
+		err := h.svc.UpdateClient(r.Context(), c)
+		if clientError(w, r, err, "failed to update client") {
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, response.NewOAuthClient(c))
+	}
+}
+
+// RotateClientSecret godoc
+// @Summary      Rotate an OAuth client secret
+// @Description  Replaces the secret of a confidential client and returns the new one, which cannot be shown again. The old secret stops working at once. Admins only
+// @Tags         clients
+// @Produce      json
+// @Param        id   path      string  true  "Client ID"
+// @Success      200  {object}  response.OAuthClientSecret
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /admin/clients/{id}/secret [post]
+// @Security     BearerAuth
+func (h *Handler) RotateClientSecret() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !permission.Admin(r.Context()) {
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, response.Error("forbidden"))
+			return
+		}
+
+		id := chi.URLParam(r, "id")
+
+		secret, err := h.svc.RotateClientSecret(r.Context(), id)
+		if clientError(w, r, err, "failed to rotate client secret") {
+			return
+		}
+
+		c, err := h.svc.GetClient(r.Context(), id)
+		if clientError(w, r, err, "failed to get client") {
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, response.OAuthClientSecret{OAuthClient: response.NewOAuthClient(c), Secret: secret})
+	}
+}
+
+// DeleteClient godoc
+// @Summary      Delete an OAuth client
+// @Description  Deletes a client and its pending authorization codes. Tokens already issued to it stay valid until they expire unless token versions are checked. Admins only
+// @Tags         clients
+// @Param        id   path      string  true  "Client ID"
+// @Success      204
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /admin/clients/{id} [delete]
+// @Security     BearerAuth
+func (h *Handler) DeleteClient() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !permission.Admin(r.Context()) {
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, response.Error("forbidden"))
+			return
+		}
+
+		err := h.svc.DeleteClient(r.Context(), chi.URLParam(r, "id"))
+		if clientError(w, r, err, "failed to delete client") {
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// decodeClientRequest decodes and validates the JSON body of r into req,
+// writing the error response when that fails.
+func decodeClientRequest(w http.ResponseWriter, r *http.Request, req any) bool {
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, response.Error("failed to render"))
+		return false
+	}
+
+	if err := validator.New().Struct(req); err != nil {
+		var validateErr validator.ValidationErrors
+		errors.As(err, &validateErr)
+
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, validate.Error(validateErr))
+		return false
+	}
+
+	return true
+}
+
+// clientError writes the response for an error of the client service and
+// reports whether there was one.
+func clientError(w http.ResponseWriter, r *http.Request, err error, msg string) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, sql.ErrNoRows):
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, response.Error("client not found"))
+	case errors.Is(err, service.InvalidScopeError),
+		errors.Is(err, service.InvalidRedirectURIError),
+		errors.Is(err, service.InvalidClientMetadataError),
+		errors.Is(err, service.PublicClientError):
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, response.Error(err.Error()))
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, response.Error(msg))
+	}
+
+	return true
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/render"
+
+	"auth/internal/entity"
+	"auth/internal/http/lib/schema/response"
+	"auth/internal/service"
+)
+
+type OIDCService interface {
+	OIDCEnabled() bool
+	UserInfo(ctx context.Context, userID int64, scopes []string) (*entity.UserInfo, error)
+}
+
+// OpenIDConfiguration godoc
+// @Summary      OpenID Connect discovery
+// @Description  Describes the OpenID Connect provider: its endpoints, including registration_endpoint when dynamic client registration is enabled, supported scopes, grants and claims. ID tokens are signed with the access token keys published at /.well-known/jwks.json.
+// @Description  OpenID Connect needs an asymmetric signing algorithm; with HS256 this endpoint returns 404 and the openid scope is refused
+// @Tags         oidc
+// @Produce      json
+// @Success      200  {object}  response.OpenIDConfiguration
+// @Failure      404  {object}  response.Response
+// @Router       /.well-known/openid-configuration [get]
+func (h *Handler) OpenIDConfiguration() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !h.svc.OIDCEnabled() {
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, response.Error("OpenID Connect needs an asymmetric signing algorithm"))
+			return
+		}
+
+		issuer := strings.TrimSuffix(h.cfg.JWT.Issuer, "/")
+
+		var registration string
+		if h.cfg.OAuth.ClientRegistrationToken != "" {
+			registration = issuer + "/oauth/register"
+		}
+
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.cfg.JWT.JWKSMaxAge.Seconds())))
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, response.OpenIDConfiguration{
+			Issuer:                            h.cfg.JWT.Issuer,
+			AuthorizationEndpoint:             issuer + "/oauth/authorize",
+			TokenEndpoint:                     issuer + "/oauth/token",
+			UserinfoEndpoint:                  issuer + "/userinfo",
+			RegistrationEndpoint:              registration,
+			JWKSURI:                           issuer + "/.well-known/jwks.json",
+			ScopesSupported:                   slices.Concat(entity.IdentityScopes, entity.Scopes),
+			ResponseTypesSupported:            []string{"code"},
+			GrantTypesSupported:               []string{grantAuthorizationCode, grantClientCredentials, grantTokenExchange},
+			SubjectTypesSupported:             []string{"public"},
+			IDTokenSigningAlgValuesSupported:  []string{h.cfg.JWT.Algorithm},
+			TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+			CodeChallengeMethodsSupported:     []string{"S256"},
+			ClaimsSupported: []string{
+				"iss", "sub", "aud", "exp", "iat", "auth_time", "nonce",
+				"preferred_username", "updated_at", "email", "email_verified",
+			},
+			AuthorizationResponseISSSupported: true,
+		})
+	}
+}
+
+// UserInfo godoc
+// @Summary      OpenID Connect userinfo
+// @Description  Returns claims about the user of the access token, which must carry the openid scope. The profile scope adds preferred_username and updated_at, the email scope adds email and email_verified
+// @Tags         oidc
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  entity.UserInfo
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /userinfo [get]
+// @Router       /userinfo [post]
+func (h *Handler) UserInfo() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.Context().Value("userID").(int64)
+		scopes, _ := r.Context().Value("scope").([]string)
+
+		info, err := h.svc.UserInfo(r.Context(), id, scopes)
+		if errors.Is(err, sql.ErrNoRows) {
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, response.Error("user not found"))
+			return
+		}
+
+		if errors.Is(err, service.UserDisabledError) || errors.Is(err, service.UserLockedError) {
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, response.Error(err.Error()))
+			return
+		}
+
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to get user info"))
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, info)
+	}
+}
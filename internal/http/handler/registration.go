@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	"auth/internal/entity"
+	"auth/internal/http/lib/schema/request"
+	"auth/internal/http/lib/schema/response"
+	"auth/internal/service"
+)
+
+// Token endpoint authentication methods of dynamic client registration.
+const (
+	authMethodNone        = "none"
+	authMethodSecretBasic = "client_secret_basic"
+)
+
+// RegisterClient godoc
+// @Summary      Dynamic client registration
+// @Description  Registers an OAuth client as in RFC 7591, authorized by the initial access token configured as oauth.client_registration_token. The endpoint is disabled when none is configured.
+// @Description  token_endpoint_auth_method none registers a public client without a secret. Redirect URIs must use https, or http on a loopback host. Registered clients get the authorization_code grant only and may request only the OpenID Connect scopes and those every user can be granted; admins grant others through /admin/clients
+// @Tags         oauth
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string                      true  "Bearer initial access token"
+// @Param        client         body      request.ClientRegistration  true  "Client metadata"
+// @Success      201  {object}  response.ClientRegistration
+// @Failure      400  {object}  response.OAuthError
+// @Failure      401  {object}  response.OAuthError
+// @Failure      404  {object}  response.OAuthError
+// @Failure      500  {object}  response.OAuthError
+// @Router       /oauth/register [post]
+func (h *Handler) RegisterClient() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="oauth"`)
+			oauthError(w, r, http.StatusUnauthorized, "invalid_token", "initial access token is required")
+			return
+		}
+
+		var req request.ClientRegistration
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			oauthError(w, r, http.StatusBadRequest, "invalid_client_metadata", "malformed client metadata")
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			oauthError(w, r, http.StatusBadRequest, "invalid_client_metadata", err.Error())
+			return
+		}
+
+		if req.TokenEndpointAuthMethod == "" {
+			req.TokenEndpointAuthMethod = authMethodSecretBasic
+		}
+
+		c := &entity.OAuthClient{
+			Name:         req.ClientName,
+			Public:       req.TokenEndpointAuthMethod == authMethodNone,
+			Scopes:       strings.Fields(req.Scope),
+			RedirectURIs: req.RedirectURIs,
+			GrantTypes:   req.GrantTypes,
+		}
+
+		secret, err := h.svc.RegisterClient(r.Context(), token, c)
+		switch {
+		case errors.Is(err, service.RegistrationDisabledError):
+			oauthError(w, r, http.StatusNotFound, "not_found", err.Error())
+			return
+		case errors.Is(err, service.InvalidTokenError):
+			w.Header().Set("WWW-Authenticate", `Bearer realm="oauth", error="invalid_token"`)
+			oauthError(w, r, http.StatusUnauthorized, "invalid_token", "invalid initial access token")
+			return
+		case errors.Is(err, service.InvalidRedirectURIError):
+			oauthError(w, r, http.StatusBadRequest, "invalid_redirect_uri", err.Error())
+			return
+		case errors.Is(err, service.InvalidScopeError), errors.Is(err, service.InvalidClientMetadataError):
+			oauthError(w, r, http.StatusBadRequest, "invalid_client_metadata", err.Error())
+			return
+		case err != nil:
+			oauthError(w, r, http.StatusInternalServerError, "server_error", "failed to register client")
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		render.JSON(w, r, response.NewClientRegistration(c, secret, req.TokenEndpointAuthMethod))
+	}
+}
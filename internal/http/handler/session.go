@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"auth/internal/entity"
+	"auth/internal/http/lib/permission"
+	"auth/internal/http/lib/schema/response"
+	"auth/internal/http/lib/utils"
+)
+
+type SessionService interface {
+	ListSessions(ctx context.Context, userID int64) ([]*entity.Session, error)
+	RevokeSession(ctx context.Context, userID int64, id string) error
+}
+
+// ListMySessions godoc
+// @Summary      List own sessions
+// @Description  Lists the devices the current user is logged in on. The session of the presented token is marked current
+// @Tags         sessions
+// @Produce      json
+// @Success      200  {array}   response.Session
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/me/sessions [get]
+// @Security     BearerAuth
+func (h *Handler) ListMySessions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.listSessions(w, r, r.Context().Value("userID").(int64))
+	}
+}
+
+// RevokeMySession godoc
+// @Summary      Revoke own session
+// @Description  Logs the current user out of one device by revoking the refresh tokens of the session. Its access tokens stay valid until they expire
+// @Tags         sessions
+// @Produce      json
+// @Param        sid  path      string  true  "Session ID"
+// @Success      204  "No Content"
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/me/sessions/{sid} [delete]
+// @Security     BearerAuth
+func (h *Handler) RevokeMySession() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.revokeSession(w, r, r.Context().Value("userID").(int64))
+	}
+}
+
+// ListUserSessions godoc
+// @Summary      List sessions of a user
+// @Description  Lists the devices a user is logged in on. Admins and OAuth clients with the scope only
+// @Tags         sessions
+// @Produce      json
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {array}   response.Session
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/{id}/sessions [get]
+// @Security     BearerAuth
+func (h *Handler) ListUserSessions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := utils.ParseID(w, r, chi.URLParam(r, "id"))
+		if err != nil {
+			return
+		}
+
+		if !(permission.Admin(r.Context()) || permission.ClientScope(r.Context(), entity.ScopeUsersRead)) {
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, response.Error("forbidden"))
+
+			return
+		}
+
+		h.listSessions(w, r, id)
+	}
+}
+
+// RevokeUserSession godoc
+// @Summary      Revoke a session of a user
+// @Description  Logs a user out of one device by revoking the refresh tokens of the session. Admins and OAuth clients with the scope only
+// @Tags         sessions
+// @Produce      json
+// @Param        id   path      int     true  "User ID"
+// @Param        sid  path      string  true  "Session ID"
+// @Success      204  "No Content"
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/{id}/sessions/{sid} [delete]
+// @Security     BearerAuth
+func (h *Handler) RevokeUserSession() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := utils.ParseID(w, r, chi.URLParam(r, "id"))
+		if err != nil {
+			return
+		}
+
+		if !(permission.Admin(r.Context()) || permission.ClientScope(r.Context(), entity.ScopeUsersWrite)) {
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, response.Error("forbidden"))
+
+			return
+		}
+
+		h.revokeSession(w, r, id)
+	}
+}
+
+func (h *Handler) listSessions(w http.ResponseWriter, r *http.Request, userID int64) {
+	sessions, err := h.svc.ListSessions(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, response.Error("user not found"))
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("failed to get sessions"))
+		return
+	}
+
+	current, _ := r.Context().Value("sessionID").(string)
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, response.NewSessions(sessions, current))
+}
+
+func (h *Handler) revokeSession(w http.ResponseWriter, r *http.Request, userID int64) {
+	err := h.svc.RevokeSession(r.Context(), userID, chi.URLParam(r, "sid"))
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, response.Error("session not found"))
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("failed to revoke session"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
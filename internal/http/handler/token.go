@@ -7,19 +7,26 @@ import (
 	"net/http"
 
 	"auth/internal/entity"
+	"auth/internal/http/lib/permission"
 	"auth/internal/http/lib/schema/request"
 	"auth/internal/http/lib/schema/response"
 	"auth/internal/http/lib/validate"
 	"auth/internal/repository/postgres"
+	"auth/internal/service"
 
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
 )
 
 type TokenService interface {
-	Register(ctx context.Context, u *entity.User) (*entity.Token, error)
-	Login(ctx context.Context, u *entity.User) (*entity.Token, error)
-	Refresh(token string) (string, error)
+	Register(ctx context.Context, u *entity.User, client entity.ClientInfo) (*entity.Token, error)
+	Login(ctx context.Context, u *entity.User, audience, scope string, client entity.ClientInfo) (*entity.Token, error)
+	Refresh(ctx context.Context, token string, client entity.ClientInfo) (*entity.Token, error)
+	RevokeToken(ctx context.Context, jti, token, reason string) (*entity.RevokedToken, error)
+	Logout(ctx context.Context, token string) error
+	LogoutAll(ctx context.Context, userID, actorID int64) error
+	ClientCredentials(ctx context.Context, clientID, secret, audience, scope string) (*entity.Token, error)
+	Impersonate(ctx context.Context, actorToken string, targetID int64, audience, scope string, client entity.ClientInfo) (*entity.Token, error)
 }
 
 // Register godoc
@@ -62,7 +69,7 @@ func (h *Handler) Register() http.HandlerFunc {
 			Email:        req.Email,
 		}
 
-		token, err := h.svc.Register(r.Context(), user)
+		token, err := h.svc.Register(r.Context(), user, clientInfo(r, req.ClientID))
 		if errors.Is(err, postgres.DuplicateError) {
 			w.WriteHeader(http.StatusConflict)
 			render.JSON(w, r, response.Error("username or email already exists"))
@@ -92,6 +99,8 @@ func (h *Handler) Register() http.HandlerFunc {
 // @Param        user  body      request.Login  true  "Login credentials"
 // @Success      200   {object}  response.Tokens
 // @Failure      400   {object}  response.Response
+// @Failure      403   {object}  response.Response
+// @Failure      404   {object}  response.Response
 // @Failure      500   {object}  response.Response
 // @Router       /auth/login [post]
 func (h *Handler) Login() http.HandlerFunc {
@@ -110,6 +119,7 @@ func (h *Handler) Login() http.HandlerFunc {
 
 			w.WriteHeader(http.StatusBadRequest)
 			render.JSON(w, r, validate.Error(validateErr))
+			return
 		}
 
 		var user = &entity.User{
@@ -117,13 +127,25 @@ func (h *Handler) Login() http.HandlerFunc {
 			PasswordHash: req.Password,
 		}
 
-		token, err := h.svc.Login(r.Context(), user)
+		token, err := h.svc.Login(r.Context(), user, req.Audience, req.Scope, clientInfo(r, req.ClientID))
 		if errors.Is(err, sql.ErrNoRows) {
 			w.WriteHeader(http.StatusNotFound)
 			render.JSON(w, r, response.Error("user not found"))
 			return
 		}
 
+		if errors.Is(err, service.InvalidAudienceError) || errors.Is(err, service.InvalidScopeError) {
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, response.Error(err.Error()))
+			return
+		}
+
+		if errors.Is(err, service.UserDisabledError) || errors.Is(err, service.UserLockedError) {
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, response.Error(err.Error()))
+			return
+		}
+
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			render.JSON(w, r, response.Error("failed to login"))
@@ -139,14 +161,17 @@ func (h *Handler) Login() http.HandlerFunc {
 }
 
 // Refresh godoc
-// @Summary      Refresh access token
-// @Description  Returns a new access token using refresh token
+// @Summary      Refresh tokens
+// @Description  Exchanges a refresh token for a new access and refresh token. Each refresh token can be used once, reusing one revokes all tokens issued from the same login
+// @Description  Errors carry a code: invalid_refresh_token, refresh_token_reused, user_not_found, user_disabled, user_locked or internal_error
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        token  body      request.Refresh  true  "Refresh token request"
-// @Success      200    {object}  response.AccessToken
+// @Success      200    {object}  response.Tokens
 // @Failure      400    {object}  response.Response
+// @Failure      401    {object}  response.Response
+// @Failure      403    {object}  response.Response
 // @Failure      500    {object}  response.Response
 // @Router       /auth/refresh [post]
 func (h *Handler) Refresh() http.HandlerFunc {
@@ -168,16 +193,186 @@ func (h *Handler) Refresh() http.HandlerFunc {
 			return
 		}
 
-		accessToken, err := h.svc.Refresh(req.RefreshToken)
+		token, err := h.svc.Refresh(r.Context(), req.RefreshToken, clientInfo(r, ""))
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, response.Error("failed to refresh token"))
+			status, code, message := refreshError(err)
+			w.WriteHeader(status)
+			render.JSON(w, r, response.ErrorCode(code, message))
 			return
 		}
 
 		w.WriteHeader(http.StatusOK)
-		render.JSON(w, r, response.AccessToken{
-			AccessToken: accessToken,
+		render.JSON(w, r, response.Tokens{
+			AccessToken:  token.AccessToken,
+			RefreshToken: token.RefreshToken,
 		})
 	}
 }
+
+// refreshError maps a refresh failure to a status, an error code and a
+// message. Internal errors are not passed on to the client.
+func refreshError(err error) (int, string, string) {
+	switch {
+	case errors.Is(err, service.InvalidRefreshTokenError):
+		return http.StatusUnauthorized, "invalid_refresh_token", err.Error()
+	case errors.Is(err, service.RefreshTokenReusedError):
+		return http.StatusUnauthorized, "refresh_token_reused", err.Error()
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusUnauthorized, "user_not_found", "user not found"
+	case errors.Is(err, service.UserDisabledError):
+		return http.StatusForbidden, "user_disabled", err.Error()
+	case errors.Is(err, service.UserLockedError):
+		return http.StatusForbidden, "user_locked", err.Error()
+	}
+
+	return http.StatusInternalServerError, "internal_error", "failed to refresh token"
+}
+
+// Logout godoc
+// @Summary      Logout
+// @Description  Revokes the refresh token and every refresh token issued from the same login
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        token  body  request.Logout  true  "Refresh token of the session"
+// @Success      204    "No Content"
+// @Failure      400    {object}  response.Response
+// @Failure      401    {object}  response.Response
+// @Failure      500    {object}  response.Response
+// @Router       /auth/logout [post]
+func (h *Handler) Logout() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req request.Logout
+
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, response.Error("failed to render"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var validateErr validator.ValidationErrors
+			errors.As(err, &validateErr)
+
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, validate.Error(validateErr))
+			return
+		}
+
+		err := h.svc.Logout(r.Context(), req.RefreshToken)
+		if errors.Is(err, service.InvalidRefreshTokenError) {
+			w.WriteHeader(http.StatusUnauthorized)
+			render.JSON(w, r, response.Error(err.Error()))
+			return
+		}
+
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to logout"))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// LogoutAll godoc
+// @Summary      Logout everywhere
+// @Description  Revokes every refresh token of the current user and the access token used for this request
+// @Tags         auth
+// @Produce      json
+// @Success      204  "No Content"
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /auth/logout-all [post]
+// @Security     BearerAuth
+func (h *Handler) LogoutAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID := ctx.Value("userID").(int64)
+
+		if err := h.svc.LogoutAll(ctx, userID, userID); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to logout"))
+			return
+		}
+
+		if jti, _ := ctx.Value("tokenID").(string); jti != "" {
+			if _, err := h.svc.RevokeToken(ctx, jti, "", "logout"); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				render.JSON(w, r, response.Error("failed to revoke access token"))
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// RevokeToken godoc
+// @Summary      Revoke a token
+// @Description  Puts an access or refresh token on the revocation list, identified by jti or by the raw token. Admins and OAuth clients with the scope only
+// @Tags         tokens
+// @Accept       json
+// @Produce      json
+// @Param        token  body      request.RevokeToken  true  "Token to revoke"
+// @Success      200    {object}  response.RevokedToken
+// @Failure      400    {object}  response.Response
+// @Failure      401    {object}  response.Response
+// @Failure      403    {object}  response.Response
+// @Failure      500    {object}  response.Response
+// @Router       /admin/tokens/revoke [post]
+// @Security     BearerAuth
+func (h *Handler) RevokeToken() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !(permission.Admin(r.Context()) || permission.ClientScope(r.Context(), entity.ScopeTokensRevoke)) {
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, response.Error("forbidden"))
+			return
+		}
+
+		var req request.RevokeToken
+
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, response.Error("failed to render"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var validateErr validator.ValidationErrors
+			errors.As(err, &validateErr)
+
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, validate.Error(validateErr))
+			return
+		}
+
+		t, err := h.svc.RevokeToken(r.Context(), req.JTI, req.Token, req.Reason)
+		if errors.Is(err, service.InvalidTokenError) {
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, response.Error("token is invalid or expired"))
+			return
+		}
+
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to revoke token"))
+			return
+		}
+
+		resp := response.RevokedToken{
+			JTI:       t.ID,
+			Reason:    t.Reason,
+			ExpiresAt: t.ExpiresAt,
+			RevokedAt: t.RevokedAt,
+		}
+
+		if t.UserID.Valid {
+			resp.UserID = &t.UserID.Int64
+		}
+
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, resp)
+	}
+}
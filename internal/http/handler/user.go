@@ -51,7 +51,7 @@ func (h *Handler) CreateUser() http.HandlerFunc {
 			return
 		}
 
-		if !permission.Admin(r.Context()) {
+		if !(permission.Admin(r.Context()) || permission.ClientScope(r.Context(), entity.ScopeUsersWrite)) {
 			w.WriteHeader(http.StatusForbidden)
 			render.JSON(w, r, response.Error("forbidden"))
 
@@ -120,7 +120,7 @@ func (h *Handler) GetUserByID() http.HandlerFunc {
 		}
 
 		ctx := r.Context()
-		if !(permission.Admin(ctx) || permission.Moderator(ctx) || permission.UserOwn(ctx, id)) {
+		if !(permission.Admin(ctx) || permission.Moderator(ctx) || permission.ClientScope(ctx, entity.ScopeUsersRead) || permission.UserOwn(ctx, id)) {
 			w.WriteHeader(http.StatusForbidden)
 			render.JSON(w, r, response.Error("forbidden"))
 
@@ -198,7 +198,7 @@ func (h *Handler) GetUserMe() http.HandlerFunc {
 // @Security     BearerAuth
 func (h *Handler) GetUserAll() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if !(permission.Admin(r.Context()) || permission.Moderator(r.Context())) {
+		if !(permission.Admin(r.Context()) || permission.Moderator(r.Context()) || permission.ClientScope(r.Context(), entity.ScopeUsersRead)) {
 			w.WriteHeader(http.StatusForbidden)
 			render.JSON(w, r, response.Error("forbidden"))
 
@@ -323,7 +323,7 @@ func (h *Handler) DeleteUserByID() http.HandlerFunc {
 			return
 		}
 
-		if !permission.Admin(r.Context()) {
+		if !(permission.Admin(r.Context()) || permission.ClientScope(r.Context(), entity.ScopeUsersWrite)) {
 			w.WriteHeader(http.StatusForbidden)
 			render.JSON(w, r, response.Error("forbidden"))
 
@@ -345,3 +345,48 @@ func (h *Handler) DeleteUserByID() http.HandlerFunc {
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
+
+// LogoutUserByID godoc
+// @Summary      Force logout of a user
+// @Description  Revokes every refresh token of the user so they have to log in again. Admins and OAuth clients with the scope only
+// @Tags         users
+// @Produce      json
+// @Param        id   path      int  true  "User ID"
+// @Success      204  "No Content"
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/{id}/logout [post]
+// @Security     BearerAuth
+func (h *Handler) LogoutUserByID() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := utils.ParseID(w, r, chi.URLParam(r, "id"))
+		if err != nil {
+			return
+		}
+
+		if !(permission.Admin(r.Context()) || permission.ClientScope(r.Context(), entity.ScopeUsersWrite)) {
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, response.Error("forbidden"))
+
+			return
+		}
+
+		actorID, _ := r.Context().Value("userID").(int64)
+
+		if err = h.svc.LogoutAll(r.Context(), id, actorID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				w.WriteHeader(http.StatusNotFound)
+				render.JSON(w, r, response.Error("user not found"))
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to logout user"))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -1,47 +1,86 @@
 package jwt
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 
 	"auth/internal/entity"
+	"auth/package/utils"
 )
 
-func GenerateToken(sub int64, role string, ttl time.Duration, secret []byte) (string, error) {
-	claims := entity.Claims{
-		Sub:  sub,
-		Role: role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+// Typ headers of issued tokens: access tokens as in RFC 9068, and ID tokens
+// as OpenID Connect clients expect.
+const (
+	TypeAccess = "at+jwt"
+	TypeID     = "JWT"
+)
+
+// signToken fills the registered claims and signs them with key. Every token
+// gets a jti so that it can be revoked on its own; a caller may set one up
+// front.
+func (m *Manager) signToken(claims jwt.Claims, registered *jwt.RegisteredClaims, typ string, ttl time.Duration, key *Key) (string, error) {
+	if registered.ID == "" {
+		id, err := utils.RandomID()
+		if err != nil {
+			return "", err
+		}
+		registered.ID = id
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(secret)
-}
+	now := time.Now()
+	registered.Issuer = m.issuer
+	registered.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+	registered.NotBefore = jwt.NewNumericDate(now)
+	registered.IssuedAt = jwt.NewNumericDate(now)
 
-func GenerateAccessToken(sub int64, role string) (string, error) {
-	return GenerateToken(sub, role, accessTokenTTL, accessSecret)
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.ID
+	token.Header["typ"] = typ
+
+	return token.SignedString(key.signKey)
 }
 
-func GenerateRefreshToken(sub int64, role string) (string, error) {
-	return GenerateToken(sub, role, refreshTokenTTL, refreshSecret)
+// GenerateAccessToken signs claims as an access token. The caller sets the
+// subject, role, version and audience; an empty audience means the
+// configured one. TokenUse defaults to entity.TokenUseAccess.
+func (m *Manager) GenerateAccessToken(claims *entity.AccessClaims) (string, error) {
+	return m.GenerateAccessTokenTTL(claims, m.accessTokenTTL)
 }
 
-func GenerateAllTokens(sub int64, role string) (*entity.Token, error) {
-	var err error
-	tokens := &entity.Token{}
-	tokens.AccessToken, err = GenerateAccessToken(sub, role)
+// GenerateAccessTokenTTL is GenerateAccessToken with a lifetime other than
+// the configured one.
+func (m *Manager) GenerateAccessTokenTTL(claims *entity.AccessClaims, ttl time.Duration) (string, error) {
+	key, err := m.keys.Active()
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+
+	if len(claims.Audience) == 0 {
+		claims.Audience = jwt.ClaimStrings{m.audience}
 	}
 
-	tokens.RefreshToken, err = GenerateRefreshToken(sub, role)
+	if claims.TokenUse == "" {
+		claims.TokenUse = entity.TokenUseAccess
+	}
+
+	return m.signToken(claims, &claims.RegisteredClaims, TypeAccess, ttl, key)
+}
+
+// GenerateIDToken signs claims as an OpenID Connect ID token with the access
+// token key, so that clients verify it through the same JWK set. The caller
+// sets the subject and the client ID as audience. HMAC keys are refused,
+// relying parties could not verify the token.
+func (m *Manager) GenerateIDToken(claims *entity.IDClaims) (string, error) {
+	key, err := m.keys.Active()
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+
+	if key.Algorithm == AlgHS256 {
+		return "", fmt.Errorf("id tokens need an asymmetric signing key, the active key %q is %s", key.ID, key.Algorithm)
 	}
 
-	return tokens, nil
+	return m.signToken(claims, &claims.RegisteredClaims, TypeID, m.accessTokenTTL, key)
 }
@@ -0,0 +1,61 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+
+	"auth/internal/entity"
+)
+
+// JWK returns the public key in JWK format. HMAC keys have no public part
+// and report false.
+func (k *Key) JWK() (entity.JWK, bool) {
+	jwk := entity.JWK{Kid: k.ID, Use: "sig", Alg: k.Algorithm}
+
+	switch pub := k.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encode(pub.N.Bytes())
+		jwk.E = encode(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		ecdh, err := pub.ECDH()
+		if err != nil {
+			return entity.JWK{}, false
+		}
+
+		// Uncompressed point: 0x04 || X || Y with fixed size coordinates.
+		point := ecdh.Bytes()
+		size := (len(point) - 1) / 2
+		jwk.Kty = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		jwk.X = encode(point[1 : 1+size])
+		jwk.Y = encode(point[1+size:])
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = encode(pub)
+	default:
+		return entity.JWK{}, false
+	}
+
+	return jwk, true
+}
+
+// JWKS returns the public keys that verify access tokens.
+func (m *Manager) JWKS() []entity.JWK {
+	keys := make([]entity.JWK, 0)
+	for _, key := range m.keys.Keys() {
+		if jwk, ok := key.JWK(); ok {
+			keys = append(keys, jwk)
+		}
+	}
+
+	return keys
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
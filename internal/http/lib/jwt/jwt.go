@@ -2,11 +2,70 @@ package jwt
 
 import (
 	"time"
-)
 
-var (
-	accessSecret    = []byte("access-secret-key")
-	refreshSecret   = []byte("refresh-secret-key")
-	accessTokenTTL  = 30 * time.Minute
-	refreshTokenTTL = 30 * 24 * time.Hour
+	"auth/internal/config"
 )
+
+type Manager struct {
+	keys           *Keyring
+	accessTokenTTL time.Duration
+	issuer         string
+	audience       string
+	leeway         time.Duration
+}
+
+// New creates a manager for cfg. With the database key store the keyring
+// starts empty and is filled through SetAccessKeys.
+func New(cfg config.JWT) (*Manager, error) {
+	m := &Manager{
+		keys:           NewKeyring(),
+		accessTokenTTL: cfg.AccessTTL,
+		issuer:         cfg.Issuer,
+		audience:       cfg.Audience,
+		leeway:         cfg.Leeway,
+	}
+
+	if cfg.KeyStore == config.KeyStoreDatabase {
+		return m, nil
+	}
+
+	var (
+		accessKey *Key
+		err       error
+	)
+
+	if cfg.Algorithm == AlgHS256 {
+		id := cfg.KeyID
+		if id == "" {
+			id = "access"
+		}
+		accessKey = NewHMACKey(id, []byte(cfg.AccessSecret))
+	} else {
+		accessKey, err = LoadKey(cfg.PrivateKeyFile, cfg.KeyID, cfg.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = m.keys.Set([]*Key{accessKey}); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// SetAccessKeys replaces the keys that sign and verify access tokens.
+func (m *Manager) SetAccessKeys(keys []*Key) error {
+	return m.keys.Set(keys)
+}
+
+// AccessKeys returns the non-retired access token keys.
+func (m *Manager) AccessKeys() []*Key {
+	return m.keys.Keys()
+}
+
+// Ready reports whether the signing keys are loaded.
+func (m *Manager) Ready() error {
+	_, err := m.keys.Active()
+	return err
+}
@@ -0,0 +1,185 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+const minRSABits = 2048
+
+var (
+	UnsupportedAlgorithmError = errors.New("unsupported signing algorithm")
+	KeyMismatchError          = errors.New("key does not match signing algorithm")
+)
+
+// Key is a signing key together with the material used to verify it. For
+// HMAC both are the shared secret, for the asymmetric algorithms the
+// private key signs and its public half verifies.
+type Key struct {
+	ID        string
+	Algorithm string
+	State     string
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+}
+
+// Public returns the public key of an asymmetric key and nil for HMAC.
+func (k *Key) Public() crypto.PublicKey {
+	if _, ok := k.verifyKey.([]byte); ok {
+		return nil
+	}
+
+	return k.verifyKey
+}
+
+func NewHMACKey(id string, secret []byte) *Key {
+	return &Key{
+		ID:        id,
+		Algorithm: AlgHS256,
+		State:     KeyActive,
+		method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+	}
+}
+
+// NewKey wraps an asymmetric private key. An empty id is replaced by a
+// fingerprint of the public key.
+func NewKey(id, algorithm string, private crypto.Signer) (*Key, error) {
+	method, err := signingMethod(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkKeyType(algorithm, private); err != nil {
+		return nil, err
+	}
+
+	if id == "" {
+		if id, err = fingerprint(private.Public()); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Key{
+		ID:        id,
+		Algorithm: algorithm,
+		State:     KeyActive,
+		method:    method,
+		signKey:   private,
+		verifyKey: private.Public(),
+	}, nil
+}
+
+// LoadKey reads a PEM encoded PKCS#8, PKCS#1 or SEC 1 private key file.
+func LoadKey(path, id, algorithm string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read private key: %w", err)
+	}
+
+	private, err := ParsePrivateKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key %s: %w", path, err)
+	}
+
+	return NewKey(id, algorithm, private)
+}
+
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var (
+		key any
+		err error
+	)
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	return signer, nil
+}
+
+func signingMethod(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case AlgHS256:
+		return jwt.SigningMethodHS256, nil
+	case AlgRS256:
+		return jwt.SigningMethodRS256, nil
+	case AlgES256:
+		return jwt.SigningMethodES256, nil
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", UnsupportedAlgorithmError, algorithm)
+}
+
+func checkKeyType(algorithm string, private crypto.Signer) error {
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		if algorithm == AlgRS256 && k.N.BitLen() >= minRSABits {
+			return nil
+		}
+	case *ecdsa.PrivateKey:
+		if algorithm == AlgES256 && k.Curve == elliptic.P256() {
+			return nil
+		}
+	case ed25519.PrivateKey:
+		if algorithm == AlgEdDSA {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s with %T", KeyMismatchError, algorithm, private)
+}
+
+func fingerprint(public crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:16]), nil
+}
@@ -0,0 +1,115 @@
+package jwt
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+const (
+	KeyActive     = "active"
+	KeyVerifyOnly = "verify_only"
+	KeyRetired    = "retired"
+)
+
+var (
+	NoActiveKeyError = errors.New("keyring has no active key")
+)
+
+// Keyring holds the access token keys. The active key signs new tokens,
+// verify-only keys still verify tokens signed before a rotation and retired
+// keys are dropped.
+type Keyring struct {
+	mu     sync.RWMutex
+	active *Key
+	keys   map[string]*Key
+}
+
+func NewKeyring() *Keyring {
+	return &Keyring{keys: make(map[string]*Key)}
+}
+
+// Set replaces the keys in the ring. Exactly one key must be active.
+func (r *Keyring) Set(keys []*Key) error {
+	var active *Key
+	byID := make(map[string]*Key, len(keys))
+
+	for _, key := range keys {
+		switch key.State {
+		case KeyActive:
+			if active != nil {
+				return errors.New("keyring has more than one active key")
+			}
+			active = key
+		case KeyRetired:
+			continue
+		}
+
+		byID[key.ID] = key
+	}
+
+	if active == nil {
+		return NoActiveKeyError
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.active = active
+	r.keys = byID
+
+	return nil
+}
+
+func (r *Keyring) Active() (*Key, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.active == nil {
+		return nil, NoActiveKeyError
+	}
+
+	return r.active, nil
+}
+
+// Lookup returns the non-retired key with the given kid.
+func (r *Keyring) Lookup(kid string) (*Key, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[kid]
+	return key, ok
+}
+
+// Keys returns the non-retired keys ordered by kid.
+func (r *Keyring) Keys() []*Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*Key, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ID < keys[j].ID
+	})
+
+	return keys
+}
+
+func (r *Keyring) algorithms() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var algorithms []string
+	for _, key := range r.keys {
+		if !seen[key.Algorithm] {
+			seen[key.Algorithm] = true
+			algorithms = append(algorithms, key.Algorithm)
+		}
+	}
+
+	return algorithms
+}
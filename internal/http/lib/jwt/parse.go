@@ -1,32 +1,95 @@
 package jwt
 
 import (
+	"fmt"
+	"slices"
+
 	"github.com/golang-jwt/jwt/v5"
 
 	"auth/internal/entity"
 )
 
-func parseToken(tokenStr string, secret []byte) (*entity.Claims, error) {
+type keyLookup func(kid string) (*Key, bool)
+
+// parseToken verifies tokenStr into claims with the key named by its kid
+// header. Tokens without a kid, with an unknown kid, signed with an
+// algorithm other than the one of the key or with a typ header other than
+// typ are rejected, as are tokens from another issuer, for another audience
+// or outside their validity window.
+func (m *Manager) parseToken(tokenStr string, claims jwt.Claims, typ string, lookup keyLookup, algorithms []string, audience string) error {
 	tokenFunc := func(t *jwt.Token) (interface{}, error) {
-		return secret, nil
+		if got, _ := t.Header["typ"].(string); got != typ {
+			return nil, fmt.Errorf("%w: typ %q, want %q", jwt.ErrTokenUnverifiable, got, typ)
+		}
+
+		kid, ok := t.Header["kid"].(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: missing kid header", jwt.ErrTokenUnverifiable)
+		}
+
+		key, ok := lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown kid %q", jwt.ErrTokenUnverifiable, kid)
+		}
+
+		if t.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("%w: unexpected alg %q for kid %q", jwt.ErrTokenSignatureInvalid, t.Method.Alg(), kid)
+		}
+
+		return key.verifyKey, nil
 	}
-	token, err := jwt.ParseWithClaims(tokenStr, &entity.Claims{}, tokenFunc)
+
+	token, err := jwt.ParseWithClaims(tokenStr, claims, tokenFunc,
+		jwt.WithValidMethods(algorithms),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithAudience(audience),
+		jwt.WithLeeway(m.leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	claims, ok := token.Claims.(*entity.Claims)
-	if !ok || !token.Valid {
-		return nil, jwt.ErrTokenInvalidClaims
+	if !token.Valid {
+		return jwt.ErrTokenInvalidClaims
 	}
 
-	return claims, nil
+	return nil
+}
+
+// checkClaims rejects tokens whose token_use claim is not one of the
+// expected uses or that have no jti.
+func checkClaims(use string, want []string, jti string) error {
+	if !slices.Contains(want, use) {
+		return fmt.Errorf("%w: token_use %q, want one of %q", jwt.ErrTokenInvalidClaims, use, want)
+	}
+
+	if jti == "" {
+		return fmt.Errorf("%w: missing jti", jwt.ErrTokenInvalidId)
+	}
+
+	return nil
 }
 
-func GetClaimsAccessToken(tokenStr string) (*entity.Claims, error) {
-	return parseToken(tokenStr, accessSecret)
+// GetClaimsAccessToken parses an access token meant for this service.
+func (m *Manager) GetClaimsAccessToken(tokenStr string) (*entity.AccessClaims, error) {
+	return m.GetClaimsAccessTokenFor(tokenStr, m.audience)
 }
 
-func GetClaimsRefreshToken(tokenStr string) (*entity.Claims, error) {
-	return parseToken(tokenStr, refreshSecret)
+// GetClaimsAccessTokenFor parses an access token meant for audience, issued
+// either to a user or to an OAuth client.
+func (m *Manager) GetClaimsAccessTokenFor(tokenStr, audience string) (*entity.AccessClaims, error) {
+	claims := &entity.AccessClaims{}
+
+	err := m.parseToken(tokenStr, claims, TypeAccess, m.keys.Lookup, m.keys.algorithms(), audience)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkClaims(claims.TokenUse, []string{entity.TokenUseAccess, entity.TokenUseClient}, claims.ID); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
 }
@@ -0,0 +1,173 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"auth/internal/config"
+	"auth/internal/entity"
+)
+
+const (
+	testIssuer   = "https://auth.test"
+	testAudience = "api"
+)
+
+// newTestManager returns a manager whose keyring holds an active EdDSA key
+// and a verify-only ES256 key.
+func newTestManager(t *testing.T) (*Manager, *Key, *Key) {
+	t.Helper()
+
+	m, err := New(config.JWT{
+		Issuer:    testIssuer,
+		Audience:  testAudience,
+		AccessTTL: time.Minute,
+		KeyStore:  config.KeyStoreDatabase,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	active := generateKey(t, AlgEdDSA)
+	verifyOnly := generateKey(t, AlgES256)
+	verifyOnly.State = KeyVerifyOnly
+
+	if err = m.SetAccessKeys([]*Key{active, verifyOnly}); err != nil {
+		t.Fatal(err)
+	}
+
+	return m, active, verifyOnly
+}
+
+func generateKey(t *testing.T, algorithm string) *Key {
+	t.Helper()
+
+	key, err := GenerateKey(algorithm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+// validClaims returns access token claims that pass every check but the
+// header ones.
+func validClaims() *entity.AccessClaims {
+	now := time.Now()
+
+	return &entity.AccessClaims{
+		Sub:      1,
+		Role:     "user",
+		TokenUse: entity.TokenUseAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "jti",
+			Issuer:    testIssuer,
+			Audience:  jwt.ClaimStrings{testAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		},
+	}
+}
+
+// sign signs claims with method and signKey under the given headers; an
+// empty header value is left out.
+func sign(t *testing.T, method jwt.SigningMethod, signKey any, kid, typ string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, validClaims())
+	delete(token.Header, "typ")
+
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	if typ != "" {
+		token.Header["typ"] = typ
+	}
+
+	s, err := token.SignedString(signKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestGetClaimsAccessTokenAcceptsIssuedTokens(t *testing.T) {
+	m, active, verifyOnly := newTestManager(t)
+
+	issued, err := m.GenerateAccessToken(&entity.AccessClaims{Sub: 1, Role: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := map[string]string{
+		"issued":      issued,
+		"active key":  sign(t, active.method, active.signKey, active.ID, TypeAccess),
+		"verify-only": sign(t, verifyOnly.method, verifyOnly.signKey, verifyOnly.ID, TypeAccess),
+	}
+
+	for name, token := range tokens {
+		if _, err = m.GetClaimsAccessToken(token); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestGetClaimsAccessTokenRejectsKeyHeaders(t *testing.T) {
+	m, active, verifyOnly := newTestManager(t)
+	unknown := generateKey(t, AlgEdDSA)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"missing kid", sign(t, active.method, active.signKey, "", TypeAccess)},
+		{"unknown kid", sign(t, unknown.method, unknown.signKey, unknown.ID, TypeAccess)},
+		{"kid of another key", sign(t, unknown.method, unknown.signKey, active.ID, TypeAccess)},
+		{"alg of another key in the ring", sign(t, verifyOnly.method, verifyOnly.signKey, active.ID, TypeAccess)},
+		{"HS256 keyed with the public key", sign(t, jwt.SigningMethodHS256, []byte(active.Public().(ed25519.PublicKey)), active.ID, TypeAccess)},
+		{"alg none", sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, active.ID, TypeAccess)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := m.GetClaimsAccessToken(tt.token); err == nil {
+				t.Fatal("token accepted")
+			}
+		})
+	}
+}
+
+func TestGetClaimsAccessTokenRejectsTyp(t *testing.T) {
+	m, active, _ := newTestManager(t)
+
+	idToken, err := m.GenerateIDToken(&entity.IDClaims{RegisteredClaims: jwt.RegisteredClaims{
+		Subject:  "1",
+		Audience: jwt.ClaimStrings{testAudience},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"missing typ", sign(t, active.method, active.signKey, active.ID, "")},
+		{"generic JWT", sign(t, active.method, active.signKey, active.ID, "JWT")},
+		{"ID token", idToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := m.GetClaimsAccessToken(tt.token); err == nil {
+				t.Fatal("token accepted")
+			}
+		})
+	}
+}
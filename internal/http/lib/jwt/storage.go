@@ -0,0 +1,128 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// GenerateKey creates a new key for algorithm. Asymmetric keys are
+// identified by the fingerprint of their public key, HMAC keys by a random
+// ID.
+func GenerateKey(algorithm string) (*Key, error) {
+	var (
+		private crypto.Signer
+		err     error
+	)
+
+	switch algorithm {
+	case AlgHS256:
+		secret := make([]byte, 32)
+		id := make([]byte, 8)
+		if _, err = rand.Read(secret); err != nil {
+			return nil, err
+		}
+		if _, err = rand.Read(id); err != nil {
+			return nil, err
+		}
+
+		return NewHMACKey(hex.EncodeToString(id), secret), nil
+	case AlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, minRSABits)
+	case AlgES256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("%w: %q", UnsupportedAlgorithmError, algorithm)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKey("", algorithm, private)
+}
+
+// KeyCipher encrypts key material with AES-256-GCM before it is stored.
+type KeyCipher struct {
+	aead cipher.AEAD
+}
+
+func NewKeyCipher(secret string) (*KeyCipher, error) {
+	if secret == "" {
+		return nil, errors.New("key encryption secret is empty")
+	}
+
+	sum := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyCipher{aead: aead}, nil
+}
+
+// Seal serializes the private part of key and encrypts it. The kid is bound
+// as additional data so a blob cannot be moved to another key row.
+func (c *KeyCipher) Seal(key *Key) ([]byte, error) {
+	var plain []byte
+
+	switch k := key.signKey.(type) {
+	case []byte:
+		plain = k
+	default:
+		der, err := x509.MarshalPKCS8PrivateKey(k)
+		if err != nil {
+			return nil, err
+		}
+		plain = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, plain, []byte(key.ID)), nil
+}
+
+// Open decrypts a blob produced by Seal and rebuilds the key.
+func (c *KeyCipher) Open(id, algorithm string, sealed []byte) (*Key, error) {
+	size := c.aead.NonceSize()
+	if len(sealed) < size {
+		return nil, errors.New("sealed key is too short")
+	}
+
+	plain, err := c.aead.Open(nil, sealed[:size], sealed[size:], []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt key %s: %w", id, err)
+	}
+
+	if algorithm == AlgHS256 {
+		return NewHMACKey(id, plain), nil
+	}
+
+	private, err := ParsePrivateKeyPEM(plain)
+	if err != nil {
+		return nil, fmt.Errorf("parse key %s: %w", id, err)
+	}
+
+	return NewKey(id, algorithm, private)
+}
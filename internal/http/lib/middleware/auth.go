@@ -7,33 +7,57 @@ import (
 
 	"github.com/go-chi/render"
 
-	"auth/internal/http/lib/jwt"
+	"auth/internal/entity"
 	"auth/internal/http/lib/schema/response"
+	"auth/internal/logger"
 )
 
-func Auth(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		const bearerPrefix = "Bearer "
-
-		authHeader := r.Header.Get("Authorization")
-		if !strings.HasPrefix(authHeader, bearerPrefix) {
-			w.WriteHeader(http.StatusUnauthorized)
-			render.JSON(w, r, response.Error("missing or invalid Authorization header"))
-			return
-		}
-
-		tokenStr := strings.TrimPrefix(authHeader, bearerPrefix)
-
-		claims, err := jwt.GetClaimsAccessToken(tokenStr)
-		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			render.JSON(w, r, response.Error("invalid token"))
-			return
-		}
-
-		ctx := context.WithValue(r.Context(), "userID", claims.Sub)
-		ctx = context.WithValue(ctx, "userRole", claims.Role)
+type TokenVerifier interface {
+	VerifyAccessToken(ctx context.Context, tokenStr string) (*entity.AccessClaims, error)
+}
 
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+func Auth(tokens TokenVerifier) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			const bearerPrefix = "Bearer "
+
+			authHeader := r.Header.Get("Authorization")
+			if !strings.HasPrefix(authHeader, bearerPrefix) {
+				w.WriteHeader(http.StatusUnauthorized)
+				render.JSON(w, r, response.Error("missing or invalid Authorization header"))
+				return
+			}
+
+			tokenStr := strings.TrimPrefix(authHeader, bearerPrefix)
+
+			claims, err := tokens.VerifyAccessToken(r.Context(), tokenStr)
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				render.JSON(w, r, response.Error("invalid token"))
+				return
+			}
+
+			logger.With(r.Context(), "user_id", claims.Sub)
+
+			ctx := context.WithValue(r.Context(), "userID", claims.Sub)
+			ctx = context.WithValue(ctx, "userRole", claims.Role)
+			ctx = context.WithValue(ctx, "tokenID", claims.ID)
+			ctx = context.WithValue(ctx, "sessionID", claims.Sid)
+			ctx = context.WithValue(ctx, "scope", strings.Fields(claims.Scope))
+
+			// Client tokens have no user; userID stays zero.
+			if claims.TokenUse == entity.TokenUseClient {
+				logger.With(r.Context(), "client_id", claims.ClientID)
+				ctx = context.WithValue(ctx, "clientID", claims.ClientID)
+			}
+
+			// Impersonation tokens act for claims.Sub on behalf of an admin.
+			if claims.Act != nil {
+				logger.With(r.Context(), "actor_id", claims.Act.Sub)
+				ctx = context.WithValue(ctx, "actorID", claims.Act.Sub)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
 }
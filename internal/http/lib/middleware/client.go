@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	"auth/internal/http/lib/permission"
+	"auth/internal/http/lib/schema/response"
+)
+
+// DenyClient rejects client credentials tokens on routes that act on the
+// current user, which such tokens do not have. It must run after Auth.
+func DenyClient(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if permission.Client(r.Context()) {
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, response.ErrorCode("user_token_required", "a user token is required"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
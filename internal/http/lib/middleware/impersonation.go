@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	"auth/internal/http/lib/permission"
+	"auth/internal/http/lib/schema/response"
+)
+
+// DenyImpersonation rejects impersonation tokens on routes for sensitive
+// actions, such as changing or deleting an account or ending its sessions.
+// It must run after Auth.
+func DenyImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := permission.Actor(r.Context()); ok {
+			w.WriteHeader(http.StatusForbidden)
+			render.JSON(w, r, response.ErrorCode("impersonation_forbidden", "not allowed while impersonating"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"auth/internal/logger"
+)
+
+// Logger stores a request scoped logger carrying the request ID and route
+// in the context and writes one access log line per request.
+func Logger(log *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			route := func() string {
+				if rctx := chi.RouteContext(r.Context()); rctx != nil {
+					return rctx.RoutePattern()
+				}
+				return ""
+			}
+
+			reqLog := logger.WithDynamic(log, "route", route).With(
+				"request_id", GetRequestID(r.Context()),
+			)
+
+			ctx := logger.WithContext(r.Context(), reqLog)
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			defer func() {
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+
+				level := slog.LevelInfo
+				if status >= http.StatusInternalServerError {
+					level = slog.LevelError
+				}
+
+				logger.FromContext(ctx, reqLog).LogAttrs(ctx, level, "request completed",
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.Int("status", status),
+					slog.Int("bytes", ww.BytesWritten()),
+					slog.Duration("duration", time.Since(start)),
+					slog.String("remote_addr", r.RemoteAddr),
+					slog.String("user_agent", r.UserAgent()),
+				)
+			}()
+
+			next.ServeHTTP(ww, r.WithContext(ctx))
+		})
+	}
+}
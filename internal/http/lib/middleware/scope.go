@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	"auth/internal/http/lib/permission"
+	"auth/internal/http/lib/schema/response"
+)
+
+// RequireScope rejects requests whose access token lacks scope. It must run
+// after Auth.
+func RequireScope(scope string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !permission.Scope(r.Context(), scope) {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, scope))
+				w.WriteHeader(http.StatusForbidden)
+				render.JSON(w, r, response.ErrorCode("insufficient_scope", "token lacks scope "+scope))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
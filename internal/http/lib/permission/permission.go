@@ -2,6 +2,7 @@ package permission
 
 import (
 	"context"
+	"slices"
 )
 
 func Admin(ctx context.Context) bool {
@@ -24,3 +25,30 @@ func UserOwn(ctx context.Context, userID int64) bool {
 	}
 	return true
 }
+
+// Scope reports whether the access token of the request was granted scope.
+func Scope(ctx context.Context, scope string) bool {
+	scopes, _ := ctx.Value("scope").([]string)
+	return slices.Contains(scopes, scope)
+}
+
+// Actor returns the admin acting through an impersonation token, if the
+// request carries one.
+func Actor(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value("actorID").(int64)
+	return id, ok
+}
+
+// Client reports whether the request carries a client credentials token.
+// What such a token may do is limited by its scopes alone.
+func Client(ctx context.Context) bool {
+	id, _ := ctx.Value("clientID").(string)
+	return id != ""
+}
+
+// ClientScope reports whether the request carries a client credentials
+// token granted scope. Client tokens never pass as admins; each admin
+// action needs its own scope.
+func ClientScope(ctx context.Context, scope string) bool {
+	return Client(ctx) && Scope(ctx, scope)
+}
@@ -0,0 +1,35 @@
+package request
+
+// ClientCreate registers an OAuth client. TTLs are in seconds, 0 keeps the
+// configured lifetime.
+type ClientCreate struct {
+	Name         string   `json:"name" validate:"required,max=100"`
+	Public       bool     `json:"public"`
+	Scopes       []string `json:"scopes"`
+	RedirectURIs []string `json:"redirect_uris" validate:"dive,url"`
+	GrantTypes   []string `json:"grant_types"`
+	AccessTTL    int64    `json:"access_ttl" validate:"min=0"`
+	RefreshTTL   int64    `json:"refresh_ttl" validate:"min=0"`
+}
+
+// ClientUpdate replaces the settings of an OAuth client.
+type ClientUpdate struct {
+	Name         string   `json:"name" validate:"required,max=100"`
+	Scopes       []string `json:"scopes"`
+	RedirectURIs []string `json:"redirect_uris" validate:"dive,url"`
+	GrantTypes   []string `json:"grant_types"`
+	AccessTTL    int64    `json:"access_ttl" validate:"min=0"`
+	RefreshTTL   int64    `json:"refresh_ttl" validate:"min=0"`
+	Disabled     bool     `json:"disabled"`
+}
+
+// ClientRegistration is client metadata for dynamic client registration,
+// RFC 7591 section 2. token_endpoint_auth_method "none" registers a public
+// client.
+type ClientRegistration struct {
+	ClientName              string   `json:"client_name" validate:"max=100"`
+	RedirectURIs            []string `json:"redirect_uris" validate:"dive,url"`
+	GrantTypes              []string `json:"grant_types"`
+	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method" validate:"omitempty,oneof=none client_secret_basic client_secret_post"`
+	Scope                   string   `json:"scope" validate:"max=500"`
+}
@@ -4,13 +4,27 @@ type Register struct {
 	Username string `json:"username" validate:"required"`
 	Password string `json:"password" validate:"required,passwd"`
 	Email    string `json:"email" validate:"omitempty,email"`
+	ClientID string `json:"client_id" validate:"max=100"`
 }
 
 type Login struct {
 	Username string `json:"username" validate:"required"`
 	Password string `json:"password" validate:"required"`
+	Audience string `json:"audience"`
+	Scope    string `json:"scope" validate:"max=500"`
+	ClientID string `json:"client_id" validate:"max=100"`
 }
 
 type Refresh struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
+
+type Logout struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type RevokeToken struct {
+	JTI    string `json:"jti" validate:"required_without=Token,excluded_with=Token"`
+	Token  string `json:"token" validate:"required_without=JTI"`
+	Reason string `json:"reason" validate:"max=255"`
+}
@@ -0,0 +1,99 @@
+package response
+
+import (
+	"strings"
+	"time"
+
+	"auth/internal/entity"
+)
+
+// OAuthClient is a registered client. TTLs are in seconds and omitted when
+// the configured lifetimes apply.
+type OAuthClient struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Type         string     `json:"client_type"`
+	Scopes       []string   `json:"scopes"`
+	RedirectURIs []string   `json:"redirect_uris"`
+	GrantTypes   []string   `json:"grant_types"`
+	AccessTTL    int64      `json:"access_ttl,omitempty"`
+	RefreshTTL   int64      `json:"refresh_ttl,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DisabledAt   *time.Time `json:"disabled_at,omitempty"`
+}
+
+// OAuthClientSecret is a client along with its secret, returned once when
+// the client is created or its secret rotated.
+type OAuthClientSecret struct {
+	OAuthClient
+	Secret string `json:"client_secret,omitempty"`
+}
+
+func NewOAuthClient(c *entity.OAuthClient) OAuthClient {
+	client := OAuthClient{
+		ID:           c.ID,
+		Name:         c.Name,
+		Type:         "confidential",
+		Scopes:       nonNil(c.Scopes),
+		RedirectURIs: nonNil(c.RedirectURIs),
+		GrantTypes:   nonNil(c.GrantTypes),
+		AccessTTL:    int64(c.AccessTTL.Seconds()),
+		RefreshTTL:   int64(c.RefreshTTL.Seconds()),
+		CreatedAt:    c.CreatedAt,
+		UpdatedAt:    c.UpdatedAt,
+	}
+
+	if c.Public {
+		client.Type = "public"
+	}
+
+	if c.DisabledAt.Valid {
+		client.DisabledAt = &c.DisabledAt.Time
+	}
+
+	return client
+}
+
+// ClientRegistration is the response of dynamic client registration,
+// RFC 7591 section 3.2.1.
+type ClientRegistration struct {
+	ClientID                string   `json:"client_id"`
+	ClientSecret            string   `json:"client_secret,omitempty"`
+	ClientIDIssuedAt        int64    `json:"client_id_issued_at"`
+	ClientSecretExpiresAt   *int64   `json:"client_secret_expires_at,omitempty"`
+	ClientName              string   `json:"client_name"`
+	RedirectURIs            []string `json:"redirect_uris"`
+	GrantTypes              []string `json:"grant_types"`
+	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method"`
+	Scope                   string   `json:"scope"`
+}
+
+func NewClientRegistration(c *entity.OAuthClient, secret, authMethod string) ClientRegistration {
+	reg := ClientRegistration{
+		ClientID:                c.ID,
+		ClientSecret:            secret,
+		ClientIDIssuedAt:        c.CreatedAt.Unix(),
+		ClientName:              c.Name,
+		RedirectURIs:            nonNil(c.RedirectURIs),
+		GrantTypes:              nonNil(c.GrantTypes),
+		TokenEndpointAuthMethod: authMethod,
+		Scope:                   strings.Join(c.Scopes, " "),
+	}
+
+	if secret != "" {
+		// The secret never expires.
+		var never int64
+		reg.ClientSecretExpiresAt = &never
+	}
+
+	return reg
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+
+	return s
+}
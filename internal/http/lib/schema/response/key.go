@@ -0,0 +1,38 @@
+package response
+
+import (
+	"time"
+
+	"auth/internal/entity"
+)
+
+type JWKS struct {
+	Keys []entity.JWK `json:"keys"`
+}
+
+type SigningKey struct {
+	ID        string     `json:"kid"`
+	Algorithm string     `json:"algorithm"`
+	State     string     `json:"state"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"`
+	RetiredAt *time.Time `json:"retired_at,omitempty"`
+}
+
+func NewSigningKey(k *entity.SigningKey) SigningKey {
+	key := SigningKey{ID: k.ID, Algorithm: k.Algorithm, State: k.State}
+
+	if !k.CreatedAt.IsZero() {
+		key.CreatedAt = &k.CreatedAt
+	}
+
+	if k.RotatedAt.Valid {
+		key.RotatedAt = &k.RotatedAt.Time
+	}
+
+	if k.RetiredAt.Valid {
+		key.RetiredAt = &k.RetiredAt.Time
+	}
+
+	return key
+}
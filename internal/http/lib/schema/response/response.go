@@ -3,6 +3,7 @@ package response
 type Response struct {
 	Status string `json:"status"`
 	Error  string `json:"error,omitempty"`
+	Code   string `json:"code,omitempty"`
 }
 
 func Error(message string) Response {
@@ -11,3 +12,13 @@ func Error(message string) Response {
 		Error:  message,
 	}
 }
+
+// ErrorCode is Error with a machine-readable code, for endpoints whose
+// clients must tell failures apart.
+func ErrorCode(code, message string) Response {
+	return Response{
+		Status: "error",
+		Error:  message,
+		Code:   code,
+	}
+}
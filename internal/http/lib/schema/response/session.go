@@ -0,0 +1,35 @@
+package response
+
+import (
+	"time"
+
+	"auth/internal/entity"
+)
+
+type Session struct {
+	ID         string    `json:"id"`
+	ClientID   string    `json:"client_id,omitempty"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
+
+// NewSessions converts sessions and marks the one with ID current.
+func NewSessions(sessions []*entity.Session, current string) []Session {
+	res := make([]Session, 0, len(sessions))
+	for _, s := range sessions {
+		res = append(res, Session{
+			ID:         s.ID,
+			ClientID:   s.ClientID,
+			UserAgent:  s.UserAgent,
+			IP:         s.IP,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			Current:    current != "" && s.ID == current,
+		})
+	}
+
+	return res
+}
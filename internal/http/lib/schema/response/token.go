@@ -1,5 +1,7 @@
 package response
 
+import "time"
+
 type AccessToken struct {
 	AccessToken string `json:"access_token"`
 }
@@ -8,3 +10,11 @@ type Tokens struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 }
+
+type RevokedToken struct {
+	JTI       string    `json:"jti"`
+	UserID    *int64    `json:"user_id,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
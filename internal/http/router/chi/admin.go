@@ -0,0 +1,35 @@
+package router
+
+import (
+	"github.com/go-chi/chi/v5"
+
+	"auth/internal/entity"
+	"auth/internal/http/handler"
+	"auth/internal/http/lib/middleware"
+)
+
+func adminRouter(h *handler.Handler, tokens middleware.TokenVerifier) func(r chi.Router) {
+	return func(r chi.Router) {
+		r.Use(middleware.Auth(tokens))
+
+		r.With(middleware.RequireScope(entity.ScopeKeysRead)).Get("/keys", h.ListKeys())
+		r.With(middleware.RequireScope(entity.ScopeKeysWrite)).Post("/keys/rotate", h.RotateKey())
+		r.With(middleware.RequireScope(entity.ScopeTokensRevoke)).Post("/tokens/revoke", h.RevokeToken())
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireScope(entity.ScopeClientsRead))
+
+			r.Get("/clients", h.ListClients())
+			r.Get("/clients/{id}", h.GetClient())
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireScope(entity.ScopeClientsWrite))
+
+			r.Post("/clients", h.CreateClient())
+			r.Put("/clients/{id}", h.UpdateClient())
+			r.Delete("/clients/{id}", h.DeleteClient())
+			r.Post("/clients/{id}/secret", h.RotateClientSecret())
+		})
+	}
+}
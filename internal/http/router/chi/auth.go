@@ -4,12 +4,15 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"auth/internal/http/handler"
+	"auth/internal/http/lib/middleware"
 )
 
-func authRouter(h *handler.Handler) func(r chi.Router) {
+func authRouter(h *handler.Handler, tokens middleware.TokenVerifier) func(r chi.Router) {
 	return func(r chi.Router) {
 		r.Post("/register", h.Register())
 		r.Post("/login", h.Login())
 		r.Post("/refresh", h.Refresh())
+		r.Post("/logout", h.Logout())
+		r.With(middleware.Auth(tokens), middleware.DenyClient, middleware.DenyImpersonation).Post("/logout-all", h.LogoutAll())
 	}
 }
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"github.com/go-chi/chi/v5"
+
+	"auth/internal/entity"
+	"auth/internal/http/handler"
+	"auth/internal/http/lib/middleware"
+)
+
+func oauthRouter(h *handler.Handler) func(r chi.Router) {
+	return func(r chi.Router) {
+		r.Get("/authorize", h.Authorize())
+		r.Post("/authorize", h.Authorize())
+		r.Post("/token", h.Token())
+		r.Post("/register", h.RegisterClient())
+	}
+}
+
+func userInfoRouter(h *handler.Handler, tokens middleware.TokenVerifier) func(r chi.Router) {
+	return func(r chi.Router) {
+		r.Use(middleware.Auth(tokens), middleware.DenyClient, middleware.RequireScope(entity.ScopeOpenID))
+
+		r.Get("/", h.UserInfo())
+		r.Post("/", h.UserInfo())
+	}
+}
@@ -1,23 +1,36 @@
 package router
 
 import (
+	"log/slog"
+
 	_ "auth/docs"
 	"auth/internal/http/handler"
 	localMW "auth/internal/http/lib/middleware"
+	"auth/internal/metrics"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
-func New(r chi.Router, h *handler.Handler) {
+func New(r chi.Router, h *handler.Handler, log *slog.Logger, tokens localMW.TokenVerifier) {
+	r.Use(localMW.RequestID)
+	r.Use(localMW.Logger(log))
 	r.Use(middleware.CleanPath)
-	r.Use(middleware.URLFormat)
+	r.Use(localMW.Metrics)
 	r.Use(middleware.Recoverer)
 	r.Use(localMW.ContentTypeJSON)
 
 	r.Get("/swagger/*", httpSwagger.WrapHandler)
+	r.Get("/healthz", h.Liveness())
+	r.Get("/readyz", h.Readiness())
+	r.Handle("/metrics", metrics.Handler())
+	r.Get("/.well-known/jwks.json", h.JWKS())
+	r.Get("/.well-known/openid-configuration", h.OpenIDConfiguration())
 
-	r.Route("/auth", authRouter(h))
-	r.Route("/users", userRouter(h))
+	r.Route("/auth", authRouter(h, tokens))
+	r.Route("/oauth", oauthRouter(h))
+	r.Route("/userinfo", userInfoRouter(h, tokens))
+	r.Route("/users", userRouter(h, tokens))
+	r.Route("/admin", adminRouter(h, tokens))
 }
@@ -0,0 +1,104 @@
+package router
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"auth/internal/config"
+	"auth/internal/http/handler"
+	"auth/internal/http/lib/jwt"
+	"auth/internal/service"
+)
+
+// newTestRouter builds the router around a service with a fresh EdDSA
+// signing key and no database.
+func newTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.JWT.Algorithm = jwt.AlgEdDSA
+	cfg.JWT.KeyStore = config.KeyStoreDatabase
+
+	tokens, err := jwt.New(cfg.JWT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := jwt.GenerateKey(jwt.AlgEdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = tokens.SetAccessKeys([]*jwt.Key{key}); err != nil {
+		t.Fatal(err)
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := service.New(nil, log, nil, tokens, cfg.JWT, cfg.OAuth)
+
+	r := chi.NewRouter()
+	New(r, handler.New(nil, log, svc, nil, cfg), log, svc)
+
+	return r
+}
+
+func get(t *testing.T, h http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+	return w
+}
+
+func TestJWKSRoute(t *testing.T) {
+	w := get(t, newTestRouter(t), "/.well-known/jwks.json")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /.well-known/jwks.json: status %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var jwks struct {
+		Keys []map[string]any `json:"keys"`
+	}
+
+	if err := json.Unmarshal(w.Body.Bytes(), &jwks); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(jwks.Keys) != 1 {
+		t.Fatalf("got %d keys, want 1", len(jwks.Keys))
+	}
+}
+
+func TestDiscoveryJWKSURI(t *testing.T) {
+	h := newTestRouter(t)
+
+	w := get(t, h, "/.well-known/openid-configuration")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /.well-known/openid-configuration: status %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var discovery struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+
+	if err := json.Unmarshal(w.Body.Bytes(), &discovery); err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(discovery.JWKSURI)
+	if err != nil || u.Path == "" {
+		t.Fatalf("invalid jwks_uri %q", discovery.JWKSURI)
+	}
+
+	if w = get(t, h, u.Path); w.Code != http.StatusOK {
+		t.Fatalf("GET %s: status %d, want %d", u.Path, w.Code, http.StatusOK)
+	}
+}
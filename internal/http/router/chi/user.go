@@ -3,19 +3,35 @@ package router
 import (
 	"github.com/go-chi/chi/v5"
 
+	"auth/internal/entity"
 	"auth/internal/http/handler"
 	"auth/internal/http/lib/middleware"
 )
 
-func userRouter(h *handler.Handler) func(r chi.Router) {
+func userRouter(h *handler.Handler, tokens middleware.TokenVerifier) func(r chi.Router) {
 	return func(r chi.Router) {
-		r.Use(middleware.Auth)
+		r.Use(middleware.Auth(tokens))
 
-		r.Post("/", h.CreateUser())
-		r.Get("/", h.GetUserAll())
-		r.Put("/{id}", h.UpdateUserByID())
-		r.Delete("/{id}", h.DeleteUserByID())
-		r.Get("/{id}", h.GetUserByID())
-		r.Get("/me", h.GetUserMe())
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireScope(entity.ScopeUsersRead))
+
+			r.Get("/", h.GetUserAll())
+			r.Get("/{id}", h.GetUserByID())
+			r.Get("/{id}/sessions", h.ListUserSessions())
+			r.With(middleware.DenyClient).Get("/me", h.GetUserMe())
+			r.With(middleware.DenyClient).Get("/me/sessions", h.ListMySessions())
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireScope(entity.ScopeUsersWrite))
+			r.Use(middleware.DenyImpersonation)
+
+			r.Post("/", h.CreateUser())
+			r.Put("/{id}", h.UpdateUserByID())
+			r.Post("/{id}/logout", h.LogoutUserByID())
+			r.Delete("/{id}/sessions/{sid}", h.RevokeUserSession())
+			r.With(middleware.DenyClient).Delete("/me/sessions/{sid}", h.RevokeMySession())
+			r.Delete("/{id}", h.DeleteUserByID())
+		})
 	}
 }
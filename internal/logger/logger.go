@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"auth/internal/config"
+)
+
+func New(cfg config.Log, out io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, fmt.Errorf("log level %q: %w", cfg.Level, err)
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+
+	switch cfg.Format {
+	case "json":
+		return slog.New(slog.NewJSONHandler(out, opts)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(out, opts)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+}
+
+type ctxKey struct{}
+
+// entry is shared by every context derived from the request, so attributes
+// added deep in the chain, such as the user ID, also reach the access log.
+type entry struct {
+	log *slog.Logger
+}
+
+func WithContext(ctx context.Context, log *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &entry{log: log})
+}
+
+// FromContext returns the request logger stored in ctx, or fallback when
+// ctx does not carry one.
+func FromContext(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if e, ok := ctx.Value(ctxKey{}).(*entry); ok {
+		return e.log
+	}
+
+	return fallback
+}
+
+// With adds attributes to the request logger stored in ctx.
+func With(ctx context.Context, args ...any) {
+	if e, ok := ctx.Value(ctxKey{}).(*entry); ok {
+		e.log = e.log.With(args...)
+	}
+}
+
+// WithDynamic returns a logger that adds key with the current result of fn
+// to every record, for values that are only known later in the request.
+func WithDynamic(log *slog.Logger, key string, fn func() string) *slog.Logger {
+	return slog.New(&dynamicHandler{Handler: log.Handler(), key: key, fn: fn})
+}
+
+type dynamicHandler struct {
+	slog.Handler
+	key string
+	fn  func() string
+}
+
+func (h *dynamicHandler) Handle(ctx context.Context, r slog.Record) error {
+	if v := h.fn(); v != "" {
+		r.AddAttrs(slog.String(h.key, v))
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *dynamicHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &dynamicHandler{Handler: h.Handler.WithAttrs(attrs), key: h.key, fn: h.fn}
+}
+
+func (h *dynamicHandler) WithGroup(name string) slog.Handler {
+	return &dynamicHandler{Handler: h.Handler.WithGroup(name), key: h.key, fn: h.fn}
+}
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"auth/internal/entity"
+)
+
+const authorizationCodeColumns = `code_hash, client_id, user_id, redirect_uri, scope, code_challenge, nonce, auth_time,
+	expires_at, created_at, used_at, COALESCE(session_id, '')`
+
+func scanAuthorizationCode(row pgx.Row, c *entity.AuthorizationCode) error {
+	return row.Scan(&c.CodeHash, &c.ClientID, &c.UserID, &c.RedirectURI, &c.Scope, &c.CodeChallenge, &c.Nonce,
+		&c.AuthTime, &c.ExpiresAt, &c.CreatedAt, &c.UsedAt, &c.SessionID)
+}
+
+func (r *Repository) CreateAuthorizationCode(ctx context.Context, c *entity.AuthorizationCode) error {
+	query := `INSERT INTO authorization_codes (code_hash, client_id, user_id, redirect_uri, scope, code_challenge, nonce, auth_time, expires_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			  RETURNING created_at`
+
+	return r.db.QueryRow(ctx, query, c.CodeHash, c.ClientID, c.UserID, c.RedirectURI, nonNil(c.Scope),
+		c.CodeChallenge, c.Nonce, c.AuthTime, c.ExpiresAt).Scan(&c.CreatedAt)
+}
+
+func (r *Repository) GetAuthorizationCode(ctx context.Context, hash string) (*entity.AuthorizationCode, error) {
+	query := `SELECT ` + authorizationCodeColumns + ` FROM authorization_codes WHERE code_hash = $1`
+
+	c := &entity.AuthorizationCode{}
+	err := scanAuthorizationCode(r.db.QueryRow(ctx, query, hash), c)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// ConsumeAuthorizationCode marks the code with the given hash as used, so
+// that every code is exchanged at most once. It returns
+// AuthorizationCodeUsedError when the code was used before. Expired codes
+// are consumed too; the caller checks ExpiresAt.
+func (r *Repository) ConsumeAuthorizationCode(ctx context.Context, hash string) error {
+	query := `UPDATE authorization_codes SET used_at = NOW() WHERE code_hash = $1 AND used_at IS NULL`
+
+	res, err := r.db.Exec(ctx, query, hash)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return AuthorizationCodeUsedError
+	}
+
+	return nil
+}
+
+// SetAuthorizationCodeSession records the session started by exchanging the
+// code, so that it can be revoked if the code is presented again.
+func (r *Repository) SetAuthorizationCodeSession(ctx context.Context, hash, sessionID string) error {
+	_, err := r.db.Exec(ctx, `UPDATE authorization_codes SET session_id = $1 WHERE code_hash = $2`, sessionID, hash)
+	return err
+}
+
+// DeleteExpiredAuthorizationCodes deletes codes a day after they expired.
+// Until then a replayed code is still recognized as used.
+func (r *Repository) DeleteExpiredAuthorizationCodes(ctx context.Context) (int64, error) {
+	res, err := r.db.Exec(ctx, `DELETE FROM authorization_codes WHERE expires_at < NOW() - INTERVAL '1 day'`)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected(), nil
+}
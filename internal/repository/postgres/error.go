@@ -3,5 +3,12 @@ package postgres
 import "errors"
 
 var (
-	DuplicateError = errors.New("duplicate key error")
+	DuplicateError   = errors.New("duplicate key error")
+	InvalidRoleError = errors.New("invalid user role")
+
+	RefreshTokenUsedError    = errors.New("refresh token already used")
+	RefreshTokenRevokedError = errors.New("refresh token revoked")
+	RefreshTokenExpiredError = errors.New("refresh token expired")
+
+	AuthorizationCodeUsedError = errors.New("authorization code already used")
 )
@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"auth/internal/entity"
+)
+
+const oauthClientColumns = `id, name, secret_hash, scopes, redirect_uris, grant_types, public, access_ttl, refresh_ttl,
+	created_at, updated_at, disabled_at`
+
+func scanOAuthClient(row pgx.Row, c *entity.OAuthClient) error {
+	return row.Scan(&c.ID, &c.Name, &c.SecretHash, &c.Scopes, &c.RedirectURIs, &c.GrantTypes, &c.Public,
+		&c.AccessTTL, &c.RefreshTTL, &c.CreatedAt, &c.UpdatedAt, &c.DisabledAt)
+}
+
+func (r *Repository) CreateOAuthClient(ctx context.Context, c *entity.OAuthClient) error {
+	query := `INSERT INTO oauth_clients (id, name, secret_hash, scopes, redirect_uris, grant_types, public, access_ttl, refresh_ttl)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			  RETURNING created_at, updated_at`
+
+	err := r.db.QueryRow(ctx, query, c.ID, c.Name, c.SecretHash, nonNil(c.Scopes), nonNil(c.RedirectURIs),
+		nonNil(c.GrantTypes), c.Public, c.AccessTTL, c.RefreshTTL).Scan(&c.CreatedAt, &c.UpdatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return DuplicateError
+	}
+
+	return err
+}
+
+func (r *Repository) GetOAuthClient(ctx context.Context, id string) (*entity.OAuthClient, error) {
+	query := `SELECT ` + oauthClientColumns + ` FROM oauth_clients WHERE id = $1`
+
+	c := &entity.OAuthClient{}
+	err := scanOAuthClient(r.db.QueryRow(ctx, query, id), c)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (r *Repository) GetOAuthClients(ctx context.Context) ([]*entity.OAuthClient, error) {
+	query := `SELECT ` + oauthClientColumns + ` FROM oauth_clients ORDER BY created_at`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var clients []*entity.OAuthClient
+
+	for rows.Next() {
+		var c entity.OAuthClient
+		if err = scanOAuthClient(rows, &c); err != nil {
+			return nil, err
+		}
+
+		clients = append(clients, &c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return clients, nil
+}
+
+// UpdateOAuthClient replaces the settings of the client. The secret and
+// whether the client is public cannot change here.
+func (r *Repository) UpdateOAuthClient(ctx context.Context, c *entity.OAuthClient) error {
+	query := `UPDATE oauth_clients
+			  SET name = $1, scopes = $2, redirect_uris = $3, grant_types = $4, access_ttl = $5, refresh_ttl = $6,
+			      disabled_at = $7, updated_at = NOW()
+			  WHERE id = $8
+			  RETURNING ` + oauthClientColumns
+
+	err := scanOAuthClient(r.db.QueryRow(ctx, query, c.Name, nonNil(c.Scopes), nonNil(c.RedirectURIs), nonNil(c.GrantTypes),
+		c.AccessTTL, c.RefreshTTL, c.DisabledAt, c.ID), c)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sql.ErrNoRows
+	}
+
+	return err
+}
+
+func (r *Repository) UpdateOAuthClientSecret(ctx context.Context, id, secretHash string) error {
+	query := `UPDATE oauth_clients SET secret_hash = $1, updated_at = NOW() WHERE id = $2`
+
+	res, err := r.db.Exec(ctx, query, secretHash, id)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteOAuthClient deletes the client along with its pending authorization
+// codes.
+func (r *Repository) DeleteOAuthClient(ctx context.Context, id string) error {
+	res, err := r.db.Exec(ctx, `DELETE FROM oauth_clients WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"auth/internal/entity"
+)
+
+const refreshTokenColumns = `id, token_hash, family_id, user_id, audience, scope, client_id,
+			  COALESCE(oauth_client_id, ''), user_agent, ip, token_version, expires_at, created_at, used_at, revoked_at`
+
+func scanRefreshToken(row pgx.Row, t *entity.RefreshToken) error {
+	return row.Scan(
+		&t.ID,
+		&t.TokenHash,
+		&t.FamilyID,
+		&t.UserID,
+		&t.Audience,
+		&t.Scope,
+		&t.ClientID,
+		&t.OAuthClientID,
+		&t.UserAgent,
+		&t.IP,
+		&t.TokenVersion,
+		&t.ExpiresAt,
+		&t.CreatedAt,
+		&t.UsedAt,
+		&t.RevokedAt,
+	)
+}
+
+// queryRower is satisfied by both the pool and a transaction.
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func insertRefreshToken(ctx context.Context, q queryRower, t *entity.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (id, token_hash, family_id, user_id, audience, scope, client_id, oauth_client_id,
+			  user_agent, ip, token_version, expires_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10, $11, $12)
+			  RETURNING created_at`
+
+	return q.QueryRow(ctx, query, t.ID, t.TokenHash, t.FamilyID, t.UserID, nonNil(t.Audience), nonNil(t.Scope),
+		t.ClientID, t.OAuthClientID, t.UserAgent, t.IP, t.TokenVersion, t.ExpiresAt).Scan(&t.CreatedAt)
+}
+
+func (r *Repository) GetRefreshTokenByHash(ctx context.Context, hash string) (*entity.RefreshToken, error) {
+	query := `SELECT ` + refreshTokenColumns + ` FROM refresh_tokens WHERE token_hash = $1`
+
+	t := &entity.RefreshToken{}
+	err := scanRefreshToken(r.db.QueryRow(ctx, query, hash), t)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// RotateRefreshToken marks the token with the given hash as used and stores
+// next in its place, in the same family and with the same user, audience,
+// scope, client and token version. The session of the family is marked as
+// seen from the user agent and IP of next. The old record is returned
+// whenever it exists, also together with RefreshTokenUsedError,
+// RefreshTokenRevokedError or RefreshTokenExpiredError when it can no longer
+// be exchanged.
+func (r *Repository) RotateRefreshToken(ctx context.Context, hash string, next *entity.RefreshToken) (*entity.RefreshToken, error) {
+	var old *entity.RefreshToken
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		query := `SELECT ` + refreshTokenColumns + ` FROM refresh_tokens WHERE token_hash = $1 FOR UPDATE`
+
+		t := &entity.RefreshToken{}
+		err := scanRefreshToken(tx.QueryRow(ctx, query, hash), t)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sql.ErrNoRows
+		}
+
+		if err != nil {
+			return err
+		}
+
+		old = t
+
+		switch {
+		case t.RevokedAt.Valid:
+			return RefreshTokenRevokedError
+		case t.UsedAt.Valid:
+			return RefreshTokenUsedError
+		case !t.ExpiresAt.After(time.Now()):
+			return RefreshTokenExpiredError
+		}
+
+		if _, err = tx.Exec(ctx, `UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1`, t.ID); err != nil {
+			return err
+		}
+
+		next.FamilyID = t.FamilyID
+		next.UserID = t.UserID
+		next.Audience = t.Audience
+		next.Scope = t.Scope
+		next.ClientID = t.ClientID
+		next.OAuthClientID = t.OAuthClientID
+		next.TokenVersion = t.TokenVersion
+
+		query = `UPDATE sessions SET last_seen_at = NOW(), user_agent = $1, ip = $2 WHERE id = $3`
+		if _, err = tx.Exec(ctx, query, next.UserAgent, next.IP, t.FamilyID); err != nil {
+			return err
+		}
+
+		return insertRefreshToken(ctx, tx, next)
+	})
+
+	return old, err
+}
+
+// RevokeRefreshTokenFamily revokes every token of the family that is not
+// revoked yet and returns how many were revoked.
+func (r *Repository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) (int64, error) {
+	query := `UPDATE refresh_tokens
+			  SET revoked_at = NOW()
+			  WHERE family_id = $1 AND revoked_at IS NULL`
+
+	res, err := r.db.Exec(ctx, query, familyID)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected(), nil
+}
+
+// RevokeRefreshToken marks a single refresh token record as revoked.
+func (r *Repository) RevokeRefreshToken(ctx context.Context, id string) (int64, error) {
+	query := `UPDATE refresh_tokens
+			  SET revoked_at = NOW()
+			  WHERE id = $1 AND revoked_at IS NULL`
+
+	res, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected(), nil
+}
+
+// RevokeUserRefreshTokens revokes every refresh token of the user that is
+// not revoked yet and returns how many were revoked.
+func (r *Repository) RevokeUserRefreshTokens(ctx context.Context, userID int64) (int64, error) {
+	query := `UPDATE refresh_tokens
+			  SET revoked_at = NOW()
+			  WHERE user_id = $1 AND revoked_at IS NULL`
+
+	res, err := r.db.Exec(ctx, query, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected(), nil
+}
+
+func (r *Repository) DeleteExpiredRefreshTokens(ctx context.Context) (int64, error) {
+	res, err := r.db.Exec(ctx, `DELETE FROM refresh_tokens WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected(), nil
+}
+
+// nonNil keeps an empty list from being stored as NULL.
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+
+	return list
+}
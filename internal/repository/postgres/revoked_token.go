@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+
+	"auth/internal/entity"
+)
+
+// CreateRevokedToken adds t to the revocation list. Revoking a jti twice
+// keeps the later expiry.
+func (r *Repository) CreateRevokedToken(ctx context.Context, t *entity.RevokedToken) error {
+	query := `INSERT INTO revoked_tokens (jti, user_id, reason, expires_at)
+			  VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (jti) DO UPDATE
+			  SET expires_at = GREATEST(revoked_tokens.expires_at, EXCLUDED.expires_at)
+			  RETURNING expires_at, revoked_at`
+
+	return r.db.QueryRow(ctx, query, t.ID, t.UserID, t.Reason, t.ExpiresAt).Scan(&t.ExpiresAt, &t.RevokedAt)
+}
+
+// GetRevokedTokens returns the revocation list entries that have not
+// expired yet.
+func (r *Repository) GetRevokedTokens(ctx context.Context) ([]*entity.RevokedToken, error) {
+	query := `SELECT jti, user_id, reason, expires_at, revoked_at
+			  FROM revoked_tokens
+			  WHERE expires_at > NOW()`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var tokens []*entity.RevokedToken
+	for rows.Next() {
+		t := &entity.RevokedToken{}
+		if err = rows.Scan(&t.ID, &t.UserID, &t.Reason, &t.ExpiresAt, &t.RevokedAt); err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, t)
+	}
+
+	return tokens, rows.Err()
+}
+
+func (r *Repository) DeleteExpiredRevokedTokens(ctx context.Context) (int64, error) {
+	res, err := r.db.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected(), nil
+}
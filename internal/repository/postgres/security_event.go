@@ -0,0 +1,20 @@
+package postgres
+
+import (
+	"context"
+
+	"auth/internal/entity"
+)
+
+func (r *Repository) CreateSecurityEvent(ctx context.Context, e *entity.SecurityEvent) error {
+	details := e.Details
+	if details == nil {
+		details = map[string]any{}
+	}
+
+	query := `INSERT INTO security_events (user_id, kind, details)
+			  VALUES ($1, $2, $3)
+			  RETURNING id, created_at`
+
+	return r.db.QueryRow(ctx, query, e.UserID, e.Kind, details).Scan(&e.ID, &e.CreatedAt)
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"auth/internal/entity"
+)
+
+// CreateSession stores a new session together with the first refresh token
+// of its family.
+func (r *Repository) CreateSession(ctx context.Context, s *entity.Session, t *entity.RefreshToken) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		query := `INSERT INTO sessions (id, user_id, client_id, user_agent, ip)
+				  VALUES ($1, $2, $3, $4, $5)
+				  RETURNING created_at, last_seen_at`
+
+		err := tx.QueryRow(ctx, query, s.ID, s.UserID, s.ClientID, s.UserAgent, s.IP).Scan(&s.CreatedAt, &s.LastSeenAt)
+		if err != nil {
+			return err
+		}
+
+		return insertRefreshToken(ctx, tx, t)
+	})
+}
+
+// GetUserSessions returns the sessions of the user that still hold a usable
+// refresh token, most recently seen first.
+func (r *Repository) GetUserSessions(ctx context.Context, userID int64) ([]*entity.Session, error) {
+	query := `SELECT s.id, s.user_id, s.client_id, s.user_agent, s.ip, s.created_at, s.last_seen_at
+			  FROM sessions s
+			  WHERE s.user_id = $1 AND EXISTS (
+				  SELECT 1 FROM refresh_tokens t
+				  WHERE t.family_id = s.id AND t.used_at IS NULL AND t.revoked_at IS NULL AND t.expires_at > NOW()
+			  )
+			  ORDER BY s.last_seen_at DESC`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var sessions []*entity.Session
+
+	for rows.Next() {
+		var s entity.Session
+		if err = rows.Scan(&s.ID, &s.UserID, &s.ClientID, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastSeenAt); err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, &s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession revokes the refresh tokens of a session of the user. It
+// returns sql.ErrNoRows when the user has no such session.
+func (r *Repository) RevokeSession(ctx context.Context, userID int64, id string) (int64, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `SELECT true FROM sessions WHERE id = $1 AND user_id = $2`, id, userID).Scan(&exists)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, sql.ErrNoRows
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return r.RevokeRefreshTokenFamily(ctx, id)
+}
+
+// DeleteOrphanSessions deletes sessions whose refresh tokens have all been
+// deleted.
+func (r *Repository) DeleteOrphanSessions(ctx context.Context) (int64, error) {
+	query := `DELETE FROM sessions s
+			  WHERE NOT EXISTS (SELECT 1 FROM refresh_tokens t WHERE t.family_id = s.id)`
+
+	res, err := r.db.Exec(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected(), nil
+}
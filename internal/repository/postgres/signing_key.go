@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"auth/internal/entity"
+)
+
+// rotationLockKey serializes rotations started by several replicas at once.
+const rotationLockKey int64 = 0x61757468_6b657973
+
+func (r *Repository) GetSigningKeys(ctx context.Context) ([]*entity.SigningKey, error) {
+	query := `SELECT kid, algorithm, private_key, state, created_at, rotated_at, retired_at
+			  FROM signing_keys
+			  ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var keys []*entity.SigningKey
+	for rows.Next() {
+		k := &entity.SigningKey{}
+		err = rows.Scan(&k.ID, &k.Algorithm, &k.PrivateKey, &k.State, &k.CreatedAt, &k.RotatedAt, &k.RetiredAt)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, k)
+	}
+
+	return keys, rows.Err()
+}
+
+// RotateSigningKey makes k the active key unless the current active key was
+// created after olderThan. The previous active key moves to previousState.
+// It reports whether the rotation happened.
+func (r *Repository) RotateSigningKey(ctx context.Context, k *entity.SigningKey, olderThan time.Time, previousState string) (bool, error) {
+	rotated := false
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, rotationLockKey); err != nil {
+			return err
+		}
+
+		var createdAt time.Time
+		err := tx.QueryRow(ctx, `SELECT created_at FROM signing_keys WHERE state = 'active'`).Scan(&createdAt)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		if err == nil && createdAt.After(olderThan) {
+			return nil
+		}
+
+		query := `UPDATE signing_keys
+				  SET state = $1,
+				      rotated_at = $2,
+				      retired_at = CASE WHEN $1 = 'retired' THEN $2 END
+				  WHERE state = 'active'`
+
+		if _, err = tx.Exec(ctx, query, previousState, k.CreatedAt); err != nil {
+			return err
+		}
+
+		query = `INSERT INTO signing_keys (kid, algorithm, private_key, state, created_at)
+				 VALUES ($1, $2, $3, 'active', $4)`
+
+		if _, err = tx.Exec(ctx, query, k.ID, k.Algorithm, k.PrivateKey, k.CreatedAt); err != nil {
+			return err
+		}
+
+		rotated = true
+		return nil
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	if rotated {
+		k.State = "active"
+	}
+
+	return rotated, nil
+}
+
+// RetireSigningKeys retires verify-only keys rotated out before the given
+// time and returns how many were retired.
+func (r *Repository) RetireSigningKeys(ctx context.Context, rotatedBefore time.Time) (int64, error) {
+	query := `UPDATE signing_keys
+			  SET state = 'retired', retired_at = NOW()
+			  WHERE state = 'verify_only' AND rotated_at < $1`
+
+	res, err := r.db.Exec(ctx, query, rotatedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected(), nil
+}
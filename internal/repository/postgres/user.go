@@ -12,15 +12,19 @@ import (
 )
 
 func (r *Repository) CreateUser(ctx context.Context, u *entity.User) error {
-	query := `INSERT INTO users (username, email, age, password_hash)
-			  VALUES ($1, $2, $3, $4) RETURNING id, role`
+	query := `INSERT INTO users (username, email, age, password_hash, role)
+			  VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), 'user')::user_role)
+			  RETURNING id, role, token_version`
 
-	err := r.db.QueryRow(ctx, query, u.Username, u.Email, u.Age, u.PasswordHash).Scan(&u.ID, &u.Role)
+	err := r.db.QueryRow(ctx, query, u.Username, u.Email, u.Age, u.PasswordHash, u.Role).Scan(&u.ID, &u.Role, &u.TokenVersion)
 
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) {
-		if pgErr.Code == "23505" {
+		switch pgErr.Code {
+		case "23505":
 			return DuplicateError
+		case "22P02":
+			return InvalidRoleError
 		}
 	}
 
@@ -32,9 +36,40 @@ func (r *Repository) CreateUser(ctx context.Context, u *entity.User) error {
 }
 
 func (r *Repository) GetUserCredentialsByUsername(ctx context.Context, u *entity.User) error {
-	query := `SELECT id, password_hash, role FROM users WHERE username = $1`
+	query := `SELECT id, password_hash, role, disabled_at, locked_until, token_version FROM users WHERE username = $1`
+
+	err := r.db.QueryRow(ctx, query, u.Username).Scan(&u.ID, &u.PasswordHash, &u.Role, &u.DisabledAt, &u.LockedUntil, &u.TokenVersion)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sql.ErrNoRows
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) GetUserByUsername(ctx context.Context, u *entity.User) error {
+	query := `SELECT id, email, age, role, created_at, updated_at, disabled_at, locked_until, token_version
+			  FROM users WHERE username = $1`
+
+	err := r.db.QueryRow(ctx, query, u.Username).Scan(
+		&u.ID,
+		&u.Email,
+		&u.Age,
+		&u.Role,
+		&u.CreatedAt,
+		&u.UpdatedAt,
+		&u.DisabledAt,
+		&u.LockedUntil,
+		&u.TokenVersion,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sql.ErrNoRows
+	}
 
-	err := r.db.QueryRow(ctx, query, u.Username).Scan(&u.ID, &u.PasswordHash, &u.Role)
 	if err != nil {
 		return err
 	}
@@ -43,7 +78,7 @@ func (r *Repository) GetUserCredentialsByUsername(ctx context.Context, u *entity
 }
 
 func (r *Repository) GetUserByID(ctx context.Context, u *entity.User) error {
-	query := `SELECT username, email, age, role, created_at, updated_at
+	query := `SELECT username, email, age, role, created_at, updated_at, disabled_at, locked_until, token_version
 			  FROM users WHERE id = $1`
 
 	err := r.db.QueryRow(ctx, query, u.ID).Scan(
@@ -53,8 +88,15 @@ func (r *Repository) GetUserByID(ctx context.Context, u *entity.User) error {
 		&u.Role,
 		&u.CreatedAt,
 		&u.UpdatedAt,
+		&u.DisabledAt,
+		&u.LockedUntil,
+		&u.TokenVersion,
 	)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sql.ErrNoRows
+	}
+
 	if err != nil {
 		return err
 	}
@@ -63,8 +105,8 @@ func (r *Repository) GetUserByID(ctx context.Context, u *entity.User) error {
 }
 
 func (r *Repository) GetAllUsers(ctx context.Context) ([]*entity.User, error) {
-	query := `SELECT id, username, email, age, role
-			  FROM users`
+	query := `SELECT id, username, email, age, role, created_at, disabled_at
+			  FROM users ORDER BY id`
 
 	rows, err := r.db.Query(ctx, query)
 	if err != nil {
@@ -77,7 +119,7 @@ func (r *Repository) GetAllUsers(ctx context.Context) ([]*entity.User, error) {
 
 	for rows.Next() {
 		var u entity.User
-		if err = rows.Scan(&u.ID, &u.Username, &u.Email, &u.Age, &u.Role); err != nil {
+		if err = rows.Scan(&u.ID, &u.Username, &u.Email, &u.Age, &u.Role, &u.CreatedAt, &u.DisabledAt); err != nil {
 			return nil, err
 		}
 
@@ -133,3 +175,123 @@ func (r *Repository) DeleteUserByID(ctx context.Context, id int64) error {
 
 	return nil
 }
+
+// UpdateUserRole changes the role and bumps the token version, so that
+// tokens carrying the old role stop working.
+func (r *Repository) UpdateUserRole(ctx context.Context, u *entity.User) error {
+	query := `UPDATE users
+			  SET role = $1::user_role, token_version = token_version + 1, updated_at = NOW()
+			  WHERE id = $2
+			  RETURNING token_version`
+
+	err := r.db.QueryRow(ctx, query, u.Role, u.ID).Scan(&u.TokenVersion)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "22P02" {
+		return InvalidRoleError
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sql.ErrNoRows
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateUserPassword changes the password hash and bumps the token version.
+func (r *Repository) UpdateUserPassword(ctx context.Context, u *entity.User) error {
+	query := `UPDATE users
+			  SET password_hash = $1, token_version = token_version + 1, updated_at = NOW()
+			  WHERE id = $2
+			  RETURNING token_version`
+
+	err := r.db.QueryRow(ctx, query, u.PasswordHash, u.ID).Scan(&u.TokenVersion)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sql.ErrNoRows
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DisableUserByID disables the user and bumps the token version.
+func (r *Repository) DisableUserByID(ctx context.Context, id int64) error {
+	query := `UPDATE users
+			  SET disabled_at = COALESCE(disabled_at, NOW()), token_version = token_version + 1, updated_at = NOW()
+			  WHERE id = $1`
+
+	res, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// LockUserByID locks the user until the given time, or unlocks them when
+// until is not valid.
+func (r *Repository) LockUserByID(ctx context.Context, id int64, until sql.NullTime) error {
+	query := `UPDATE users
+			  SET locked_until = $1, updated_at = NOW()
+			  WHERE id = $2`
+
+	res, err := r.db.Exec(ctx, query, until, id)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// BumpUserTokenVersion invalidates every token issued to the user so far.
+func (r *Repository) BumpUserTokenVersion(ctx context.Context, id int64) (int64, error) {
+	query := `UPDATE users
+			  SET token_version = token_version + 1
+			  WHERE id = $1
+			  RETURNING token_version`
+
+	var version int64
+	err := r.db.QueryRow(ctx, query, id).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, sql.ErrNoRows
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// GetUserTokenVersion returns the token version of an enabled user. Deleted
+// and disabled users yield sql.ErrNoRows.
+func (r *Repository) GetUserTokenVersion(ctx context.Context, id int64) (int64, error) {
+	query := `SELECT token_version FROM users WHERE id = $1 AND disabled_at IS NULL`
+
+	var version int64
+	err := r.db.QueryRow(ctx, query, id).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, sql.ErrNoRows
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"auth/internal/entity"
+	"auth/internal/logger"
+	"auth/internal/metrics"
+	"auth/internal/repository/postgres"
+	"auth/package/utils"
+)
+
+// authorizationCodeTTL is how long an authorization code can be exchanged.
+const authorizationCodeTTL = time.Minute
+
+type AuthorizationRepository interface {
+	CreateAuthorizationCode(ctx context.Context, c *entity.AuthorizationCode) error
+	GetAuthorizationCode(ctx context.Context, hash string) (*entity.AuthorizationCode, error)
+	ConsumeAuthorizationCode(ctx context.Context, hash string) error
+	SetAuthorizationCodeSession(ctx context.Context, hash, sessionID string) error
+	DeleteExpiredAuthorizationCodes(ctx context.Context) (int64, error)
+}
+
+// ValidateAuthorization checks an authorization request before the user
+// signs in: the client must exist with c.RedirectURI registered and the
+// authorization code grant, the
+// space-separated scope must be allowed for the client, all of its scopes
+// when empty, and c.CodeChallenge must be a PKCE S256 challenge. Every
+// client may request the OpenID Connect scopes, unless ID tokens cannot be
+// issued with the configured algorithm. It fills c.Scope.
+func (s *Service) ValidateAuthorization(ctx context.Context, c *entity.AuthorizationCode, scope string) error {
+	client, err := s.repo.GetOAuthClient(ctx, c.ClientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return InvalidClientError
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if client.DisabledAt.Valid {
+		return InvalidClientError
+	}
+
+	if !slices.Contains(client.RedirectURIs, c.RedirectURI) {
+		return InvalidRedirectURIError
+	}
+
+	if !slices.Contains(client.GrantTypes, entity.GrantAuthorizationCode) {
+		return UnauthorizedClientError
+	}
+
+	scopes := strings.Fields(scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+
+	if len(scopes) == 0 {
+		return InvalidScopeError
+	}
+
+	for _, sc := range scopes {
+		if !slices.Contains(client.Scopes, sc) && !slices.Contains(entity.IdentityScopes, sc) {
+			return InvalidScopeError
+		}
+	}
+
+	if slices.Contains(scopes, entity.ScopeOpenID) && !s.OIDCEnabled() {
+		return InvalidScopeError
+	}
+
+	if !validPKCE(c.CodeChallenge) {
+		return InvalidCodeChallengeError
+	}
+
+	c.Scope = scopes
+	return nil
+}
+
+// Authorize signs the user in for an authorization request and returns a
+// single-use authorization code for the client. u carries the username and
+// the plain password, as for Login.
+func (s *Service) Authorize(ctx context.Context, u *entity.User, c *entity.AuthorizationCode, scope string) (string, error) {
+	const op = "oauth.service.Authorize"
+	log := logger.FromContext(ctx, s.log)
+
+	if err := s.ValidateAuthorization(ctx, c, scope); err != nil {
+		log.Warn("invalid authorization request", "op", op, "client_id", c.ClientID, "error", err)
+		metrics.AuthOutcome("authorize", "invalid_request")
+		return "", err
+	}
+
+	if err := s.verifyCredentials(ctx, u, "authorize"); err != nil {
+		return "", err
+	}
+
+	code, err := utils.RandomToken()
+	if err != nil {
+		log.Error("failed", "op", op, "error", err)
+		metrics.AuthOutcome("authorize", "token_error")
+		return "", err
+	}
+
+	now := time.Now()
+	c.CodeHash = utils.HashToken(code)
+	c.UserID = u.ID
+	c.AuthTime = now
+	c.ExpiresAt = now.Add(authorizationCodeTTL)
+
+	if err = s.repo.CreateAuthorizationCode(ctx, c); err != nil {
+		log.Error("failed to store authorization code", "op", op, "error", err)
+		metrics.AuthOutcome("authorize", "storage_error")
+		return "", err
+	}
+
+	log.Debug("success", "op", op, "id", u.ID, "client_id", c.ClientID)
+	metrics.AuthOutcome("authorize", "")
+	return code, nil
+}
+
+// ExchangeCode redeems an authorization code for a new session of the user,
+// after checking that it was issued to the client for redirectURI and that
+// verifier answers its PKCE challenge. Confidential clients must
+// authenticate with their secret. Codes granted the openid scope also yield
+// an ID token. A code is only consumed once every check passed, so other
+// clients cannot burn it; presenting a consumed code again revokes the
+// session it started.
+func (s *Service) ExchangeCode(ctx context.Context, code, clientID, secret, redirectURI, verifier string, info entity.ClientInfo) (*entity.Token, error) {
+	const op = "oauth.service.ExchangeCode"
+	log := logger.FromContext(ctx, s.log)
+
+	client, err := s.repo.GetOAuthClient(ctx, clientID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Error("failed to get client", "op", op, "error", err)
+		metrics.AuthOutcome("authorization_code", "storage_error")
+		return nil, err
+	}
+
+	if err == nil && !client.Public {
+		client, err = s.authenticateClient(ctx, clientID, secret)
+		if err != nil && !errors.Is(err, InvalidClientError) {
+			log.Error("failed to authenticate client", "op", op, "error", err)
+			metrics.AuthOutcome("authorization_code", "storage_error")
+			return nil, err
+		}
+	}
+
+	if err != nil || client.DisabledAt.Valid {
+		log.Warn("client authentication failed", "op", op, "client_id", clientID)
+		metrics.AuthOutcome("authorization_code", "invalid_client")
+		return nil, InvalidClientError
+	}
+
+	if !slices.Contains(client.GrantTypes, entity.GrantAuthorizationCode) {
+		log.Warn("client not registered for the grant", "op", op, "client_id", clientID)
+		metrics.AuthOutcome("authorization_code", "unauthorized_client")
+		return nil, UnauthorizedClientError
+	}
+
+	hash := utils.HashToken(code)
+
+	c, err := s.repo.GetAuthorizationCode(ctx, hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		metrics.AuthOutcome("authorization_code", "unknown_code")
+		return nil, InvalidGrantError
+	}
+
+	if err != nil {
+		log.Error("failed to get authorization code", "op", op, "error", err)
+		metrics.AuthOutcome("authorization_code", "storage_error")
+		return nil, err
+	}
+
+	switch {
+	case c.ClientID != client.ID, c.RedirectURI != redirectURI:
+		log.Warn("authorization code presented by another client or for another redirect", "op", op, "client_id", clientID)
+		metrics.AuthOutcome("authorization_code", "mismatch")
+		return nil, InvalidGrantError
+	case c.UsedAt.Valid:
+		s.revokeCodeSession(ctx, c, info)
+		return nil, InvalidGrantError
+	case !c.ExpiresAt.After(time.Now()):
+		metrics.AuthOutcome("authorization_code", "expired")
+		return nil, InvalidGrantError
+	case !verifyPKCE(c.CodeChallenge, verifier):
+		log.Warn("PKCE verification failed", "op", op, "client_id", clientID)
+		metrics.AuthOutcome("authorization_code", "invalid_verifier")
+		return nil, InvalidGrantError
+	}
+
+	err = s.repo.ConsumeAuthorizationCode(ctx, hash)
+	if errors.Is(err, postgres.AuthorizationCodeUsedError) {
+		// Exchanged concurrently; the session is not recorded yet, if at all.
+		s.revokeCodeSession(ctx, c, info)
+		return nil, InvalidGrantError
+	}
+
+	if err != nil {
+		log.Error("failed to consume authorization code", "op", op, "error", err)
+		metrics.AuthOutcome("authorization_code", "storage_error")
+		return nil, err
+	}
+
+	u := &entity.User{ID: c.UserID}
+	if err = s.repo.GetUserByID(ctx, u); err != nil {
+		log.Error("failed to get user by id", "op", op, "error", err)
+		metrics.AuthOutcome("authorization_code", "storage_error")
+		return nil, err
+	}
+
+	if err = checkActive(u); err != nil {
+		metrics.AuthOutcome("authorization_code", inactiveOutcome(err))
+		return nil, InvalidGrantError
+	}
+
+	info.ClientID = client.ID
+
+	tokens, err := s.issueTokens(ctx, u, []string{s.cfg.Audience}, c.Scope, info)
+	if err != nil {
+		log.Error("failed to generate tokens", "op", op, "error", err)
+		metrics.AuthOutcome("authorization_code", "token_error")
+		return nil, err
+	}
+
+	if err = s.repo.SetAuthorizationCodeSession(ctx, hash, tokens.SessionID); err != nil {
+		log.Error("failed to record session of authorization code", "op", op, "error", err)
+	}
+
+	if slices.Contains(c.Scope, entity.ScopeOpenID) {
+		tokens.IDToken, err = s.tokens.GenerateIDToken(idClaims(u, c))
+		if err != nil {
+			log.Error("failed to generate id token", "op", op, "error", err)
+			metrics.AuthOutcome("authorization_code", "token_error")
+			return nil, err
+		}
+	}
+
+	log.Debug("success", "op", op, "id", u.ID, "client_id", client.ID)
+	metrics.AuthOutcome("authorization_code", "")
+	return tokens, nil
+}
+
+// revokeCodeSession handles an authorization code presented after it was
+// exchanged: the session it started is revoked, since the code may have
+// leaked, and the attempt is recorded.
+func (s *Service) revokeCodeSession(ctx context.Context, c *entity.AuthorizationCode, info entity.ClientInfo) {
+	const op = "oauth.service.RevokeCodeSession"
+	log := logger.FromContext(ctx, s.log)
+
+	log.Warn("authorization code reuse detected", "op", op, "id", c.UserID, "client_id", c.ClientID, "session", c.SessionID)
+	metrics.AuthOutcome("authorization_code", "reused")
+
+	if c.SessionID == "" {
+		return
+	}
+
+	s.revokeFamily(ctx, &entity.RefreshToken{FamilyID: c.SessionID, UserID: c.UserID, ClientID: c.ClientID},
+		entity.EventAuthorizationCodeReuse, info)
+}
+
+// validPKCE reports whether s has the length and alphabet of a PKCE code
+// challenge or verifier, RFC 7636 section 4.1.
+func validPKCE(s string) bool {
+	if len(s) < 43 || len(s) > 128 {
+		return false
+	}
+
+	for _, r := range s {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || strings.ContainsRune("-._~", r)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// verifyPKCE checks verifier against an S256 challenge.
+func verifyPKCE(challenge, verifier string) bool {
+	if !validPKCE(verifier) {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	return subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(sum[:])), []byte(challenge)) == 1
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"auth/internal/entity"
+	"auth/package/utils"
+)
+
+// A PKCE verifier and its S256 challenge, BASE64URL(SHA256(verifier)).
+const (
+	testVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFxEjXk"
+	testChallenge = "hokoR1WYmP6ccc2QXYHPWcC2mKlCRr0_no-kijbG_No"
+)
+
+const (
+	testClientID    = "app"
+	testRedirectURI = "https://app.example/callback"
+)
+
+func TestVerifyPKCE(t *testing.T) {
+	tests := []struct {
+		name      string
+		challenge string
+		verifier  string
+		want      bool
+	}{
+		{"matching verifier", testChallenge, testVerifier, true},
+		{"other verifier", testChallenge, "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFxEjXx", false},
+		{"plain challenge", testVerifier, testVerifier, false},
+		{"short verifier", testChallenge, "dBjftJeZ4CVP", false},
+		{"invalid characters", testChallenge, "dBjftJeZ4CVP+mB92K27uhbUJU1p1r/wW1gFWFxEjXk", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyPKCE(tt.challenge, tt.verifier); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newCodeFixture stores a user, a public client and an authorization code
+// issued to it, and returns the code.
+func newCodeFixture(repo *fakeRepository) string {
+	repo.users[1] = &entity.User{ID: 1, Role: "user"}
+	repo.clients[testClientID] = &entity.OAuthClient{
+		ID:           testClientID,
+		Public:       true,
+		Scopes:       []string{entity.ScopeUsersRead},
+		RedirectURIs: []string{testRedirectURI},
+		GrantTypes:   []string{entity.GrantAuthorizationCode},
+	}
+	repo.clients["other"] = &entity.OAuthClient{
+		ID:           "other",
+		Public:       true,
+		RedirectURIs: []string{testRedirectURI},
+		GrantTypes:   []string{entity.GrantAuthorizationCode},
+	}
+
+	code := "authorization-code"
+	repo.codes[utils.HashToken(code)] = &entity.AuthorizationCode{
+		CodeHash:      utils.HashToken(code),
+		ClientID:      testClientID,
+		UserID:        1,
+		RedirectURI:   testRedirectURI,
+		Scope:         []string{entity.ScopeUsersRead},
+		CodeChallenge: testChallenge,
+		ExpiresAt:     time.Now().Add(time.Minute),
+	}
+
+	return code
+}
+
+func TestExchangeCodeChecksBeforeConsuming(t *testing.T) {
+	repo := newFakeRepository()
+	s := newTestService(t, repo)
+	ctx := context.Background()
+	code := newCodeFixture(repo)
+
+	tests := []struct {
+		name                            string
+		clientID, redirectURI, verifier string
+		want                            error
+	}{
+		{"wrong verifier", testClientID, testRedirectURI, "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFxEjXx", InvalidGrantError},
+		{"missing verifier", testClientID, testRedirectURI, "", InvalidGrantError},
+		{"other redirect", testClientID, "https://app.example/other", testVerifier, InvalidGrantError},
+		{"other client", "other", testRedirectURI, testVerifier, InvalidGrantError},
+		{"unknown client", "unknown", testRedirectURI, testVerifier, InvalidClientError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.ExchangeCode(ctx, code, tt.clientID, "", tt.redirectURI, tt.verifier, entity.ClientInfo{})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	tokens, err := s.ExchangeCode(ctx, code, testClientID, "", testRedirectURI, testVerifier, entity.ClientInfo{})
+	if err != nil {
+		t.Fatalf("failed attempts consumed the code: %v", err)
+	}
+
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+		t.Fatalf("incomplete token pair: %+v", tokens)
+	}
+}
+
+func TestExchangeCodeReuseRevokesSession(t *testing.T) {
+	repo := newFakeRepository()
+	s := newTestService(t, repo)
+	ctx := context.Background()
+	code := newCodeFixture(repo)
+
+	tokens, err := s.ExchangeCode(ctx, code, testClientID, "", testRedirectURI, testVerifier, entity.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.ExchangeCode(ctx, code, testClientID, "", testRedirectURI, testVerifier, entity.ClientInfo{})
+	if !errors.Is(err, InvalidGrantError) {
+		t.Fatalf("second exchange: got %v, want %v", err, InvalidGrantError)
+	}
+
+	if !repo.familyRevoked(tokens.SessionID) {
+		t.Fatal("session of the code not revoked after reuse")
+	}
+
+	if _, err = s.Refresh(ctx, tokens.RefreshToken, entity.ClientInfo{}); !errors.Is(err, InvalidRefreshTokenError) {
+		t.Fatalf("refresh after reuse: got %v, want %v", err, InvalidRefreshTokenError)
+	}
+
+	if !slices.Contains(repo.eventKinds(), entity.EventAuthorizationCodeReuse) {
+		t.Fatalf("no %s security event, got %v", entity.EventAuthorizationCodeReuse, repo.eventKinds())
+	}
+}
+
+func TestExchangeCodeExpired(t *testing.T) {
+	repo := newFakeRepository()
+	s := newTestService(t, repo)
+	code := newCodeFixture(repo)
+	repo.codes[utils.HashToken(code)].ExpiresAt = time.Now().Add(-time.Second)
+
+	_, err := s.ExchangeCode(context.Background(), code, testClientID, "", testRedirectURI, testVerifier, entity.ClientInfo{})
+	if !errors.Is(err, InvalidGrantError) {
+		t.Fatalf("got %v, want %v", err, InvalidGrantError)
+	}
+}
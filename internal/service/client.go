@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	"auth/internal/entity"
+	"auth/internal/logger"
+	"auth/internal/metrics"
+	"auth/package/utils"
+)
+
+type ClientRepository interface {
+	CreateOAuthClient(ctx context.Context, c *entity.OAuthClient) error
+	GetOAuthClient(ctx context.Context, id string) (*entity.OAuthClient, error)
+	GetOAuthClients(ctx context.Context) ([]*entity.OAuthClient, error)
+	UpdateOAuthClient(ctx context.Context, c *entity.OAuthClient) error
+	UpdateOAuthClientSecret(ctx context.Context, id, secretHash string) error
+	DeleteOAuthClient(ctx context.Context, id string) error
+}
+
+// CreateClient registers an OAuth client allowed to request c.Scopes and
+// returns its secret, which is not stored and cannot be shown again. Public
+// clients get no secret and can only use the authorization code grant.
+// Without grant types a client gets every grant its type and redirect URIs
+// allow.
+func (s *Service) CreateClient(ctx context.Context, c *entity.OAuthClient) (string, error) {
+	const op = "client.service.Create"
+	log := logger.FromContext(ctx, s.log)
+
+	if len(c.GrantTypes) == 0 {
+		c.GrantTypes = defaultGrantTypes(c)
+	}
+
+	if err := s.validateClient(c); err != nil {
+		return "", err
+	}
+
+	var err error
+	c.ID, err = utils.RandomID()
+	if err != nil {
+		log.Error("failed", "op", op, "error", err)
+		return "", err
+	}
+
+	var secret string
+	if !c.Public {
+		secret, err = utils.RandomToken()
+		if err != nil {
+			log.Error("failed", "op", op, "error", err)
+			return "", err
+		}
+
+		c.SecretHash = utils.HashToken(secret)
+	}
+
+	if err = s.repo.CreateOAuthClient(ctx, c); err != nil {
+		log.Error("failed", "op", op, "error", err)
+		return "", err
+	}
+
+	log.Info("oauth client created", "op", op, "client_id", c.ID, "scopes", c.Scopes, "grant_types", c.GrantTypes)
+	return secret, nil
+}
+
+// RegisterClient creates a client through dynamic client registration
+// (RFC 7591), authorized by the configured initial access token. Such
+// clients act only on behalf of users: they get the authorization code
+// grant alone and may only request scopes any user can be granted and the
+// OpenID Connect ones.
+func (s *Service) RegisterClient(ctx context.Context, initialToken string, c *entity.OAuthClient) (string, error) {
+	const op = "client.service.Register"
+	log := logger.FromContext(ctx, s.log)
+
+	if s.oauth.ClientRegistrationToken == "" {
+		return "", RegistrationDisabledError
+	}
+
+	if subtle.ConstantTimeCompare([]byte(initialToken), []byte(s.oauth.ClientRegistrationToken)) != 1 {
+		log.Warn("client registration with invalid initial access token", "op", op)
+		return "", InvalidTokenError
+	}
+
+	for _, scope := range c.Scopes {
+		if !slices.Contains(entity.RoleScopes["user"], scope) && !slices.Contains(entity.IdentityScopes, scope) {
+			return "", InvalidScopeError
+		}
+	}
+
+	for _, grant := range c.GrantTypes {
+		if grant != entity.GrantAuthorizationCode {
+			return "", fmt.Errorf("%w: only the authorization code grant can be registered", InvalidClientMetadataError)
+		}
+	}
+
+	c.GrantTypes = []string{entity.GrantAuthorizationCode}
+	c.AccessTTL, c.RefreshTTL = 0, 0
+	return s.CreateClient(ctx, c)
+}
+
+func (s *Service) GetClient(ctx context.Context, id string) (*entity.OAuthClient, error) {
+	const op = "client.service.Get"
+	log := logger.FromContext(ctx, s.log)
+
+	c, err := s.repo.GetOAuthClient(ctx, id)
+	if err != nil {
+		log.Error("failed", "op", op, "client_id", id, "error", err)
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (s *Service) ListClients(ctx context.Context) ([]*entity.OAuthClient, error) {
+	const op = "client.service.List"
+	log := logger.FromContext(ctx, s.log)
+
+	clients, err := s.repo.GetOAuthClients(ctx)
+	if err != nil {
+		log.Error("failed", "op", op, "error", err)
+		return nil, err
+	}
+
+	log.Debug("success", "op", op, "count", len(clients))
+	return clients, nil
+}
+
+// UpdateClient replaces the settings of the client c.ID. Whether the client
+// is public, and when it was disabled if it stays so, is kept from the
+// stored client.
+func (s *Service) UpdateClient(ctx context.Context, c *entity.OAuthClient) error {
+	const op = "client.service.Update"
+	log := logger.FromContext(ctx, s.log)
+
+	stored, err := s.repo.GetOAuthClient(ctx, c.ID)
+	if err != nil {
+		log.Error("failed to get client", "op", op, "client_id", c.ID, "error", err)
+		return err
+	}
+
+	c.Public = stored.Public
+	if c.DisabledAt.Valid && stored.DisabledAt.Valid {
+		c.DisabledAt = stored.DisabledAt
+	}
+
+	if len(c.GrantTypes) == 0 {
+		c.GrantTypes = defaultGrantTypes(c)
+	}
+
+	if err = s.validateClient(c); err != nil {
+		return err
+	}
+
+	if err = s.repo.UpdateOAuthClient(ctx, c); err != nil {
+		log.Error("failed", "op", op, "client_id", c.ID, "error", err)
+		return err
+	}
+
+	log.Info("oauth client updated", "op", op, "client_id", c.ID)
+	return nil
+}
+
+// RotateClientSecret replaces the secret of a confidential client and
+// returns the new one. The old secret stops working at once.
+func (s *Service) RotateClientSecret(ctx context.Context, id string) (string, error) {
+	const op = "client.service.RotateSecret"
+	log := logger.FromContext(ctx, s.log)
+
+	c, err := s.repo.GetOAuthClient(ctx, id)
+	if err != nil {
+		log.Error("failed to get client", "op", op, "client_id", id, "error", err)
+		return "", err
+	}
+
+	if c.Public {
+		return "", PublicClientError
+	}
+
+	secret, err := utils.RandomToken()
+	if err != nil {
+		log.Error("failed", "op", op, "error", err)
+		return "", err
+	}
+
+	if err = s.repo.UpdateOAuthClientSecret(ctx, id, utils.HashToken(secret)); err != nil {
+		log.Error("failed", "op", op, "client_id", id, "error", err)
+		return "", err
+	}
+
+	log.Info("oauth client secret rotated", "op", op, "client_id", id)
+	return secret, nil
+}
+
+// DeleteClient deletes the client. Tokens already issued to it stay valid
+// until they expire unless token versions are checked on access.
+func (s *Service) DeleteClient(ctx context.Context, id string) error {
+	const op = "client.service.Delete"
+	log := logger.FromContext(ctx, s.log)
+
+	if err := s.repo.DeleteOAuthClient(ctx, id); err != nil {
+		log.Error("failed", "op", op, "client_id", id, "error", err)
+		return err
+	}
+
+	log.Info("oauth client deleted", "op", op, "client_id", id)
+	return nil
+}
+
+// ClientCredentials authenticates an OAuth client and issues it an access
+// token for audience with the requested scopes, all of its scopes when scope
+// is empty. The token carries the client ID as sub and has no refresh token.
+func (s *Service) ClientCredentials(ctx context.Context, clientID, secret, audience, scope string) (*entity.Token, error) {
+	const op = "client.service.ClientCredentials"
+	log := logger.FromContext(ctx, s.log)
+
+	c, err := s.authenticateClient(ctx, clientID, secret)
+	if err != nil {
+		log.Warn("client authentication failed", "op", op, "client_id", clientID, "error", err)
+		metrics.AuthOutcome("client_credentials", "invalid_client")
+		return nil, err
+	}
+
+	if !slices.Contains(c.GrantTypes, entity.GrantClientCredentials) {
+		log.Warn("client not registered for the grant", "op", op, "client_id", clientID)
+		metrics.AuthOutcome("client_credentials", "unauthorized_client")
+		return nil, UnauthorizedClientError
+	}
+
+	aud, err := s.resolveAudience(audience)
+	if err != nil {
+		metrics.AuthOutcome("client_credentials", "invalid_audience")
+		return nil, err
+	}
+
+	scopes := strings.Fields(scope)
+	if len(scopes) == 0 {
+		scopes = c.Scopes
+	}
+
+	for _, sc := range scopes {
+		if !slices.Contains(c.Scopes, sc) {
+			metrics.AuthOutcome("client_credentials", "invalid_scope")
+			return nil, InvalidScopeError
+		}
+	}
+
+	claims := &entity.AccessClaims{
+		ClientID: c.ID,
+		Scope:    strings.Join(scopes, " "),
+		TokenUse: entity.TokenUseClient,
+	}
+	claims.Audience = aud
+
+	token, err := s.signAccessToken(claims, clientTTL(c.AccessTTL, s.cfg.AccessTTL))
+	if err != nil {
+		log.Error("failed to generate access token", "op", op, "error", err)
+		metrics.AuthOutcome("client_credentials", "token_error")
+		return nil, err
+	}
+
+	log.Debug("success", "op", op, "client_id", c.ID)
+	metrics.AuthOutcome("client_credentials", "")
+	return token, nil
+}
+
+// defaultGrantTypes are the grants a client without explicit grant types
+// gets: the authorization code grant when it has redirect URIs, and the
+// client credentials grant unless it is public.
+func defaultGrantTypes(c *entity.OAuthClient) []string {
+	var grants []string
+	if len(c.RedirectURIs) > 0 {
+		grants = append(grants, entity.GrantAuthorizationCode)
+	}
+
+	if !c.Public {
+		grants = append(grants, entity.GrantClientCredentials)
+	}
+
+	return grants
+}
+
+// validateClient checks the settings of a client: known scopes, OpenID
+// Connect ones included, and grant types, redirect URIs for the authorization code grant, no client
+// credentials grant for public clients and token lifetimes between zero and
+// the configured ones.
+func (s *Service) validateClient(c *entity.OAuthClient) error {
+	for _, scope := range c.Scopes {
+		if !slices.Contains(entity.Scopes, scope) && !slices.Contains(entity.IdentityScopes, scope) {
+			return InvalidScopeError
+		}
+	}
+
+	for _, uri := range c.RedirectURIs {
+		if !validRedirectURI(uri) {
+			return InvalidRedirectURIError
+		}
+	}
+
+	if len(c.GrantTypes) == 0 {
+		return fmt.Errorf("%w: the client needs a grant type", InvalidClientMetadataError)
+	}
+
+	for _, grant := range c.GrantTypes {
+		if !slices.Contains(entity.GrantTypes, grant) {
+			return fmt.Errorf("%w: unknown grant type %q", InvalidClientMetadataError, grant)
+		}
+	}
+
+	if slices.Contains(c.GrantTypes, entity.GrantAuthorizationCode) && len(c.RedirectURIs) == 0 {
+		return InvalidRedirectURIError
+	}
+
+	if c.Public && slices.Contains(c.GrantTypes, entity.GrantClientCredentials) {
+		return fmt.Errorf("%w: public clients cannot use the client credentials grant", InvalidClientMetadataError)
+	}
+
+	if c.AccessTTL < 0 || c.RefreshTTL < 0 {
+		return fmt.Errorf("%w: token lifetimes must not be negative", InvalidClientMetadataError)
+	}
+
+	if c.AccessTTL > s.cfg.AccessTTL {
+		return fmt.Errorf("%w: access_ttl must not exceed %d seconds", InvalidClientMetadataError, int64(s.cfg.AccessTTL.Seconds()))
+	}
+
+	if c.RefreshTTL > s.cfg.RefreshTTL {
+		return fmt.Errorf("%w: refresh_ttl must not exceed %d seconds", InvalidClientMetadataError, int64(s.cfg.RefreshTTL.Seconds()))
+	}
+
+	return nil
+}
+
+// validRedirectURI reports whether uri is an absolute https URI without a
+// fragment, RFC 6749 section 3.1.2. Plain http is only allowed for loopback
+// hosts, RFC 8252 section 7.3.
+func validRedirectURI(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" || strings.Contains(uri, "#") {
+		return false
+	}
+
+	switch u.Scheme {
+	case "https":
+		return true
+	case "http":
+		return loopbackHost(u.Hostname())
+	default:
+		return false
+	}
+}
+
+// loopbackHost reports whether host is localhost or a loopback address.
+func loopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// authenticateClient checks the secret of an enabled client. Every failure
+// is reported as InvalidClientError.
+func (s *Service) authenticateClient(ctx context.Context, clientID, secret string) (*entity.OAuthClient, error) {
+	c, err := s.repo.GetOAuthClient(ctx, clientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, InvalidClientError
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(c.SecretHash), []byte(utils.HashToken(secret))) != 1 || c.DisabledAt.Valid {
+		return nil, InvalidClientError
+	}
+
+	return c, nil
+}
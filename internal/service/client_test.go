@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"auth/internal/entity"
+)
+
+func TestCreateClientScopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		want   error
+	}{
+		{"api scopes", []string{entity.ScopeUsersRead, entity.ScopeKeysWrite}, nil},
+		{"identity scopes", []string{entity.ScopeOpenID, entity.ScopeProfile, entity.ScopeEmail}, nil},
+		{"unknown scope", []string{entity.ScopeOpenID, "unknown"}, InvalidScopeError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, newFakeRepository())
+
+			c := &entity.OAuthClient{Name: "app", Scopes: tt.scopes}
+			if _, err := s.CreateClient(context.Background(), c); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterClientScopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		want   error
+	}{
+		{"identity scopes", []string{entity.ScopeOpenID, entity.ScopeProfile, entity.ScopeEmail}, nil},
+		{"admin scope", []string{entity.ScopeOpenID, entity.ScopeKeysWrite}, InvalidScopeError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, newFakeRepository())
+			s.oauth.ClientRegistrationToken = "initial-token"
+
+			c := &entity.OAuthClient{
+				Name:         "app",
+				Public:       true,
+				Scopes:       tt.scopes,
+				RedirectURIs: []string{testRedirectURI},
+			}
+			if _, err := s.RegisterClient(context.Background(), "initial-token", c); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
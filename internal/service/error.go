@@ -0,0 +1,33 @@
+package service
+
+import "errors"
+
+var (
+	UserDisabledError     = errors.New("user is disabled")
+	UserLockedError       = errors.New("user is locked")
+	KeyStoreReadOnlyError = errors.New("signing keys come from the config file and cannot be rotated")
+	RotationConflictError = errors.New("signing key was rotated concurrently")
+
+	InvalidRefreshTokenError = errors.New("invalid refresh token")
+	RefreshTokenReusedError  = errors.New("refresh token reuse detected")
+
+	InvalidTokenError = errors.New("invalid token")
+	TokenRevokedError = errors.New("token revoked")
+	StaleTokenError   = errors.New("token predates the current token version of the user")
+
+	InvalidAudienceError = errors.New("audience is not allowed")
+	InvalidScopeError    = errors.New("scope is not allowed")
+
+	ImpersonationForbiddenError = errors.New("only admins may impersonate users")
+	ImpersonationTargetError    = errors.New("user cannot be impersonated")
+
+	InvalidClientError        = errors.New("invalid client credentials")
+	InvalidRedirectURIError   = errors.New("invalid or unregistered redirect_uri")
+	InvalidCodeChallengeError = errors.New("code_challenge must be a PKCE S256 challenge")
+	InvalidGrantError         = errors.New("invalid authorization code")
+	UnauthorizedClientError   = errors.New("client is not registered for this grant")
+
+	InvalidClientMetadataError = errors.New("invalid client metadata")
+	PublicClientError          = errors.New("public clients have no secret")
+	RegistrationDisabledError  = errors.New("dynamic client registration is disabled")
+)
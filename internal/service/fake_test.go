@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"auth/internal/config"
+	"auth/internal/entity"
+	"auth/internal/http/lib/jwt"
+	"auth/internal/repository/postgres"
+)
+
+// fakeRepository keeps users, sessions, refresh tokens, clients and
+// authorization codes in memory and follows the contract of the Postgres
+// repository for them. Calls to any other method panic.
+type fakeRepository struct {
+	Repository
+
+	mu       sync.Mutex
+	users    map[int64]*entity.User
+	tokens   map[string]*entity.RefreshToken
+	sessions map[string]*entity.Session
+	clients  map[string]*entity.OAuthClient
+	codes    map[string]*entity.AuthorizationCode
+	events   []*entity.SecurityEvent
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		users:    make(map[int64]*entity.User),
+		tokens:   make(map[string]*entity.RefreshToken),
+		sessions: make(map[string]*entity.Session),
+		clients:  make(map[string]*entity.OAuthClient),
+		codes:    make(map[string]*entity.AuthorizationCode),
+	}
+}
+
+// newTestService returns a service with HS256 access tokens on top of repo.
+func newTestService(t *testing.T, repo Repository) *Service {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.JWT.AccessSecret = "test-secret"
+
+	tokens, err := jwt.New(cfg.JWT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return New(nil, slog.New(slog.NewTextHandler(io.Discard, nil)), repo, tokens, cfg.JWT, cfg.OAuth)
+}
+
+func (r *fakeRepository) GetUserByID(_ context.Context, u *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[u.ID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	*u = *stored
+	return nil
+}
+
+func (r *fakeRepository) CreateSession(_ context.Context, s *entity.Session, t *entity.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s.CreatedAt = time.Now()
+	s.LastSeenAt = s.CreatedAt
+	r.sessions[s.ID] = s
+	r.insertToken(t)
+
+	return nil
+}
+
+func (r *fakeRepository) insertToken(t *entity.RefreshToken) {
+	t.CreatedAt = time.Now()
+	stored := *t
+	r.tokens[t.TokenHash] = &stored
+}
+
+func (r *fakeRepository) GetRefreshTokenByHash(_ context.Context, hash string) (*entity.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[hash]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	found := *t
+	return &found, nil
+}
+
+func (r *fakeRepository) RotateRefreshToken(_ context.Context, hash string, next *entity.RefreshToken) (*entity.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[hash]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	old := *t
+
+	switch {
+	case t.RevokedAt.Valid:
+		return &old, postgres.RefreshTokenRevokedError
+	case t.UsedAt.Valid:
+		return &old, postgres.RefreshTokenUsedError
+	case !t.ExpiresAt.After(time.Now()):
+		return &old, postgres.RefreshTokenExpiredError
+	}
+
+	t.UsedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	next.FamilyID = t.FamilyID
+	next.UserID = t.UserID
+	next.Audience = t.Audience
+	next.Scope = t.Scope
+	next.ClientID = t.ClientID
+	next.OAuthClientID = t.OAuthClientID
+	next.TokenVersion = t.TokenVersion
+	r.insertToken(next)
+
+	return &old, nil
+}
+
+func (r *fakeRepository) RevokeRefreshTokenFamily(_ context.Context, familyID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var revoked int64
+	for _, t := range r.tokens {
+		if t.FamilyID == familyID && !t.RevokedAt.Valid {
+			t.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			revoked++
+		}
+	}
+
+	return revoked, nil
+}
+
+func (r *fakeRepository) CreateSecurityEvent(_ context.Context, e *entity.SecurityEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, e)
+	return nil
+}
+
+func (r *fakeRepository) GetOAuthClient(_ context.Context, id string) (*entity.OAuthClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.clients[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	found := *c
+	return &found, nil
+}
+
+func (r *fakeRepository) CreateOAuthClient(_ context.Context, c *entity.OAuthClient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c.CreatedAt = time.Now()
+	stored := *c
+	r.clients[c.ID] = &stored
+
+	return nil
+}
+
+func (r *fakeRepository) GetAuthorizationCode(_ context.Context, hash string) (*entity.AuthorizationCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.codes[hash]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	found := *c
+	return &found, nil
+}
+
+func (r *fakeRepository) ConsumeAuthorizationCode(_ context.Context, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.codes[hash]
+	if !ok || c.UsedAt.Valid {
+		return postgres.AuthorizationCodeUsedError
+	}
+
+	c.UsedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (r *fakeRepository) SetAuthorizationCodeSession(_ context.Context, hash, sessionID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if c, ok := r.codes[hash]; ok {
+		c.SessionID = sessionID
+	}
+
+	return nil
+}
+
+// familyRevoked reports whether every refresh token of the family is
+// revoked.
+func (r *fakeRepository) familyRevoked(familyID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tokens {
+		if t.FamilyID == familyID && !t.RevokedAt.Valid {
+			return false
+		}
+	}
+
+	return true
+}
+
+// eventKinds returns the kinds of the recorded security events in order.
+func (r *fakeRepository) eventKinds() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kinds := make([]string, 0, len(r.events))
+	for _, e := range r.events {
+		kinds = append(kinds, e.Kind)
+	}
+
+	return kinds
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"auth/internal/config"
+	"auth/internal/entity"
+	"auth/internal/http/lib/jwt"
+	"auth/internal/logger"
+)
+
+type KeyRepository interface {
+	GetSigningKeys(ctx context.Context) ([]*entity.SigningKey, error)
+	RotateSigningKey(ctx context.Context, k *entity.SigningKey, olderThan time.Time, previousState string) (bool, error)
+	RetireSigningKeys(ctx context.Context, rotatedBefore time.Time) (int64, error)
+}
+
+// LoadKeys fills the token manager from the database key store, creating the
+// first key when the store is empty. It does nothing for the file key store.
+func (s *Service) LoadKeys(ctx context.Context) error {
+	const op = "key.service.Load"
+	log := logger.FromContext(ctx, s.log)
+
+	if s.cfg.KeyStore != config.KeyStoreDatabase {
+		return nil
+	}
+
+	var err error
+	s.cipher, err = jwt.NewKeyCipher(s.cfg.KeyEncryptionSecret)
+	if err != nil {
+		log.Error("failed", "op", op, "error", err)
+		return err
+	}
+
+	if _, err = s.rotate(ctx, onlyIfMissing, jwt.KeyVerifyOnly); err != nil {
+		log.Error("failed to create first signing key", "op", op, "error", err)
+		return err
+	}
+
+	if err = s.reloadKeys(ctx); err != nil {
+		log.Error("failed", "op", op, "error", err)
+		return err
+	}
+
+	log.Info("signing keys loaded", "op", op, "count", len(s.tokens.AccessKeys()))
+	return nil
+}
+
+// RotateKey makes a freshly generated key active. The previous key keeps
+// verifying tokens until they expire, unless retirePrevious is set, in which
+// case tokens it signed are rejected right away.
+func (s *Service) RotateKey(ctx context.Context, retirePrevious bool) (*entity.SigningKey, error) {
+	const op = "key.service.Rotate"
+	log := logger.FromContext(ctx, s.log)
+
+	if s.cfg.KeyStore != config.KeyStoreDatabase {
+		return nil, KeyStoreReadOnlyError
+	}
+
+	previous := jwt.KeyVerifyOnly
+	if retirePrevious {
+		previous = jwt.KeyRetired
+	}
+
+	k, err := s.rotate(ctx, 0, previous)
+	if err != nil {
+		log.Error("failed", "op", op, "error", err)
+		return nil, err
+	}
+
+	if k == nil {
+		log.Warn("another replica rotated the key first", "op", op)
+		return nil, RotationConflictError
+	}
+
+	if err = s.reloadKeys(ctx); err != nil {
+		log.Error("failed to reload signing keys", "op", op, "error", err)
+		return nil, err
+	}
+
+	log.Info("signing key rotated", "op", op, "kid", k.ID, "previous_state", previous)
+	return k, nil
+}
+
+// ListKeys returns every key of the database key store, newest first, or the
+// configured key for the file key store.
+func (s *Service) ListKeys(ctx context.Context) ([]*entity.SigningKey, error) {
+	const op = "key.service.List"
+	log := logger.FromContext(ctx, s.log)
+
+	if s.cfg.KeyStore != config.KeyStoreDatabase {
+		var keys []*entity.SigningKey
+		for _, key := range s.tokens.AccessKeys() {
+			keys = append(keys, &entity.SigningKey{ID: key.ID, Algorithm: key.Algorithm, State: key.State})
+		}
+
+		return keys, nil
+	}
+
+	keys, err := s.repo.GetSigningKeys(ctx)
+	if err != nil {
+		log.Error("failed", "op", op, "error", err)
+		return nil, err
+	}
+
+	log.Debug("success", "op", op, "count", len(keys))
+	return keys, nil
+}
+
+// RunKeyRotation is a background worker for the database key store. Every
+// key refresh interval it rotates the active key once it is older than the
+// rotation interval, retires verify-only keys whose tokens have expired and
+// reloads the keyring so that replicas pick up rotations made elsewhere.
+func (s *Service) RunKeyRotation(ctx context.Context) {
+	const op = "key.service.RunRotation"
+
+	ticker := time.NewTicker(s.cfg.KeyRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if s.cfg.RotationInterval > 0 {
+			k, err := s.rotate(ctx, s.cfg.RotationInterval, jwt.KeyVerifyOnly)
+			if err != nil {
+				s.log.Error("failed to rotate signing key", "op", op, "error", err)
+			} else if k != nil {
+				s.log.Info("signing key rotated on schedule", "op", op, "kid", k.ID)
+			}
+		}
+
+		// Replicas keep signing with the old key until their next reload, so
+		// its tokens may be issued up to one refresh interval after rotation.
+		retired, err := s.repo.RetireSigningKeys(ctx, time.Now().Add(-s.cfg.AccessTTL-s.cfg.KeyRefreshInterval))
+		if err != nil {
+			s.log.Error("failed to retire signing keys", "op", op, "error", err)
+		} else if retired > 0 {
+			s.log.Info("signing keys retired", "op", op, "count", retired)
+		}
+
+		if err = s.reloadKeys(ctx); err != nil {
+			s.log.Error("failed to reload signing keys", "op", op, "error", err)
+		}
+	}
+}
+
+// onlyIfMissing makes rotate create a key only when there is no active one.
+const onlyIfMissing time.Duration = -1
+
+// rotate stores a new active key unless the current one is younger than
+// maxAge. It returns nil when no rotation was needed.
+func (s *Service) rotate(ctx context.Context, maxAge time.Duration, previousState string) (*entity.SigningKey, error) {
+	key, err := jwt.GenerateKey(s.cfg.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := s.cipher.Seal(key)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &entity.SigningKey{
+		ID:         key.ID,
+		Algorithm:  key.Algorithm,
+		PrivateKey: sealed,
+		CreatedAt:  time.Now(),
+	}
+
+	var olderThan time.Time
+	if maxAge != onlyIfMissing {
+		olderThan = k.CreatedAt.Add(-maxAge)
+	}
+
+	rotated, err := s.repo.RotateSigningKey(ctx, k, olderThan, previousState)
+	if err != nil || !rotated {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+func (s *Service) reloadKeys(ctx context.Context) error {
+	stored, err := s.repo.GetSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	var keys []*jwt.Key
+	for _, k := range stored {
+		if k.State == jwt.KeyRetired {
+			continue
+		}
+
+		key, err := s.cipher.Open(k.ID, k.Algorithm, k.PrivateKey)
+		if err != nil {
+			return err
+		}
+
+		key.State = k.State
+		keys = append(keys, key)
+	}
+
+	return s.tokens.SetAccessKeys(keys)
+}
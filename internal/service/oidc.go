@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"auth/internal/entity"
+	"auth/internal/logger"
+)
+
+// OIDCEnabled reports whether ID tokens can be issued: relying parties can
+// only verify them when they are signed with an asymmetric algorithm.
+func (s *Service) OIDCEnabled() bool {
+	return s.cfg.Algorithm != "HS256"
+}
+
+// UserInfo returns the OpenID Connect claims about the user that the scopes
+// grant: the username and update time for profile, and the email address
+// for email.
+func (s *Service) UserInfo(ctx context.Context, userID int64, scopes []string) (*entity.UserInfo, error) {
+	const op = "oidc.service.UserInfo"
+	log := logger.FromContext(ctx, s.log)
+
+	u := &entity.User{ID: userID}
+	if err := s.repo.GetUserByID(ctx, u); err != nil {
+		log.Error("failed to get user by id", "op", op, "error", err)
+		return nil, err
+	}
+
+	if err := checkActive(u); err != nil {
+		return nil, err
+	}
+
+	log.Debug("success", "op", op, "id", u.ID)
+	return &entity.UserInfo{
+		Sub:     strconv.FormatInt(u.ID, 10),
+		Profile: profile(u, scopes),
+	}, nil
+}
+
+// idClaims describes the ID token for the user signed in through the
+// authorization code c.
+func idClaims(u *entity.User, c *entity.AuthorizationCode) *entity.IDClaims {
+	claims := &entity.IDClaims{
+		Nonce:    c.Nonce,
+		AuthTime: jwt.NewNumericDate(c.AuthTime),
+		Profile:  profile(u, c.Scope),
+	}
+	claims.Subject = strconv.FormatInt(u.ID, 10)
+	claims.Audience = jwt.ClaimStrings{c.ClientID}
+
+	return claims
+}
+
+// profile maps u to the standard claims the scopes grant. Email addresses
+// are never verified by this service.
+func profile(u *entity.User, scopes []string) entity.Profile {
+	var p entity.Profile
+
+	if slices.Contains(scopes, entity.ScopeProfile) {
+		p.PreferredUsername = u.Username
+		p.UpdatedAt = u.UpdatedAt.Unix()
+	}
+
+	if slices.Contains(scopes, entity.ScopeEmail) && u.Email != "" {
+		verified := false
+		p.Email = u.Email
+		p.EmailVerified = &verified
+	}
+
+	return p
+}
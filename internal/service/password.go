@@ -0,0 +1,22 @@
+package service
+
+import (
+	"time"
+
+	"auth/internal/metrics"
+	"auth/package/utils"
+)
+
+func (s *Service) hashPassword(password string) (string, error) {
+	defer observeBcrypt("hash", time.Now())
+	return utils.HashPassword(password)
+}
+
+func (s *Service) checkPassword(hash, password string) error {
+	defer observeBcrypt("compare", time.Now())
+	return utils.CheckPasswordHash(hash, password)
+}
+
+func observeBcrypt(operation string, start time.Time) {
+	metrics.BcryptDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
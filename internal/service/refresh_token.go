@@ -0,0 +1,269 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"auth/internal/entity"
+	"auth/internal/logger"
+	"auth/package/utils"
+)
+
+// refreshTokenCleanupInterval is how often expired refresh token records are
+// deleted.
+const refreshTokenCleanupInterval = time.Hour
+
+// issueTokens starts a new session for the user and returns the first token
+// pair of it. Access tokens of the session are issued for audience with the
+// requested scopes, all of the role when scope is empty, and its refresh
+// token family is bound to the current token version of u. Token lifetimes
+// follow client.ClientID, the OAuth client that authenticated, if any; the
+// label only names the session.
+func (s *Service) issueTokens(ctx context.Context, u *entity.User, audience, scope []string, client entity.ClientInfo) (*entity.Token, error) {
+	accessTTL, refreshTTL := s.tokenTTL(ctx, client.ClientID)
+
+	id, err := utils.RandomID()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &entity.Session{
+		ID:        id,
+		UserID:    u.ID,
+		ClientID:  cmp.Or(client.ClientID, client.Label),
+		UserAgent: client.UserAgent,
+		IP:        client.IP,
+	}
+
+	t, refreshToken, err := s.newRefreshToken(client, refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	t.FamilyID = session.ID
+	t.UserID = u.ID
+	t.Audience = audience
+	t.Scope = scope
+	t.ClientID = session.ClientID
+	t.OAuthClientID = client.ClientID
+	t.TokenVersion = u.TokenVersion
+
+	if err = s.repo.CreateSession(ctx, session, t); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.signAccessToken(accessClaims(u, t), accessTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens.RefreshToken = refreshToken
+	tokens.SessionID = session.ID
+	return tokens, nil
+}
+
+// tokenTTL returns the access and refresh token lifetimes for sessions of
+// the authenticated OAuth client: its overrides if it still exists, the
+// configured ones otherwise or when clientID is empty.
+func (s *Service) tokenTTL(ctx context.Context, clientID string) (access, refresh time.Duration) {
+	access, refresh = s.cfg.AccessTTL, s.cfg.RefreshTTL
+	if clientID == "" {
+		return access, refresh
+	}
+
+	c, err := s.repo.GetOAuthClient(ctx, clientID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.FromContext(ctx, s.log).Error("failed to get client, using default token lifetimes", "client_id", clientID, "error", err)
+		}
+		return access, refresh
+	}
+
+	return clientTTL(c.AccessTTL, access), clientTTL(c.RefreshTTL, refresh)
+}
+
+// clientTTL is the override ttl of a client, or def when ttl is zero or
+// longer than def. Revocation entries and retired signing keys are only kept
+// for the configured lifetimes, so no token may outlive them.
+func clientTTL(ttl, def time.Duration) time.Duration {
+	if ttl <= 0 || ttl > def {
+		return def
+	}
+
+	return ttl
+}
+
+// signAccessToken signs claims as an access token valid for ttl.
+func (s *Service) signAccessToken(claims *entity.AccessClaims, ttl time.Duration) (*entity.Token, error) {
+	accessToken, err := s.tokens.GenerateAccessTokenTTL(claims, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Token{AccessToken: accessToken, ExpiresAt: claims.ExpiresAt.Time, Scope: claims.Scope}, nil
+}
+
+// accessClaims describes an access token issued to u along with the refresh
+// token t. Scopes the role no longer allows are dropped.
+func accessClaims(u *entity.User, t *entity.RefreshToken) *entity.AccessClaims {
+	claims := &entity.AccessClaims{
+		Sub:   u.ID,
+		Role:  u.Role,
+		Scope: grantedScope(u.Role, t.Scope),
+		Ver:   u.TokenVersion,
+		Sid:   t.FamilyID,
+	}
+	claims.Audience = t.Audience
+
+	return claims
+}
+
+// newRefreshToken creates an opaque refresh token and the record that
+// stores its hash, valid for ttl. The caller fills in family, user and
+// audience.
+func (s *Service) newRefreshToken(client entity.ClientInfo, ttl time.Duration) (*entity.RefreshToken, string, error) {
+	id, err := utils.RandomID()
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, err := utils.RandomToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	t := &entity.RefreshToken{
+		ID:        id,
+		TokenHash: utils.HashToken(token),
+		UserAgent: client.UserAgent,
+		IP:        client.IP,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	return t, token, nil
+}
+
+// resolveAudience checks a requested audience against the configuration.
+// An empty request means this service.
+func (s *Service) resolveAudience(requested string) ([]string, error) {
+	switch {
+	case requested == "":
+		return []string{s.cfg.Audience}, nil
+	case requested == s.cfg.Audience || slices.Contains(s.cfg.AllowedAudiences, requested):
+		return []string{requested}, nil
+	}
+
+	return nil, InvalidAudienceError
+}
+
+// resolveScope checks space-separated requested scopes against the role.
+// An empty request means every scope of the role.
+func resolveScope(role, requested string) ([]string, error) {
+	scopes := strings.Fields(requested)
+	for _, scope := range scopes {
+		if !roleAllows(role, scope) {
+			return nil, InvalidScopeError
+		}
+	}
+
+	return scopes, nil
+}
+
+// grantedScope is the scope claim for requested scopes: those the role
+// allows, or all of its scopes when none were requested.
+func grantedScope(role string, requested []string) string {
+	if len(requested) == 0 {
+		return strings.Join(entity.RoleScopes[role], " ")
+	}
+
+	var granted []string
+	for _, scope := range requested {
+		if roleAllows(role, scope) {
+			granted = append(granted, scope)
+		}
+	}
+
+	return strings.Join(granted, " ")
+}
+
+// roleAllows reports whether the role may be granted scope. OpenID Connect
+// scopes are allowed to everyone.
+func roleAllows(role, scope string) bool {
+	return slices.Contains(entity.RoleScopes[role], scope) || slices.Contains(entity.IdentityScopes, scope)
+}
+
+// revokeFamily revokes the family of t and records why as a security
+// event. Failures are logged only, the caller rejects the request anyway.
+func (s *Service) revokeFamily(ctx context.Context, t *entity.RefreshToken, kind string, client entity.ClientInfo) {
+	const op = "token.service.RevokeFamily"
+	log := logger.FromContext(ctx, s.log)
+
+	revoked, err := s.repo.RevokeRefreshTokenFamily(ctx, t.FamilyID)
+	if err != nil {
+		log.Error("failed to revoke refresh token family", "op", op, "family", t.FamilyID, "error", err)
+	}
+
+	event := &entity.SecurityEvent{
+		UserID: sql.NullInt64{Int64: t.UserID, Valid: true},
+		Kind:   kind,
+		Details: map[string]any{
+			"family_id":  t.FamilyID,
+			"token_id":   t.ID,
+			"client_id":  t.ClientID,
+			"revoked":    revoked,
+			"ip":         client.IP,
+			"user_agent": client.UserAgent,
+		},
+	}
+
+	if err = s.repo.CreateSecurityEvent(ctx, event); err != nil {
+		log.Error("failed to record security event", "op", op, "kind", kind, "error", err)
+	}
+}
+
+// RunRefreshTokenCleanup is a background worker that deletes expired refresh
+// token records, the sessions left without any and expired authorization
+// codes.
+func (s *Service) RunRefreshTokenCleanup(ctx context.Context) {
+	const op = "token.service.RunCleanup"
+
+	ticker := time.NewTicker(refreshTokenCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		deleted, err := s.repo.DeleteExpiredRefreshTokens(ctx)
+		if err != nil {
+			s.log.Error("failed to delete expired refresh tokens", "op", op, "error", err)
+			continue
+		}
+
+		s.log.Debug("expired refresh tokens deleted", "op", op, "count", deleted)
+
+		deleted, err = s.repo.DeleteOrphanSessions(ctx)
+		if err != nil {
+			s.log.Error("failed to delete sessions", "op", op, "error", err)
+			continue
+		}
+
+		s.log.Debug("sessions without refresh tokens deleted", "op", op, "count", deleted)
+
+		deleted, err = s.repo.DeleteExpiredAuthorizationCodes(ctx)
+		if err != nil {
+			s.log.Error("failed to delete expired authorization codes", "op", op, "error", err)
+			continue
+		}
+
+		s.log.Debug("expired authorization codes deleted", "op", op, "count", deleted)
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"auth/internal/entity"
+)
+
+// startSession stores u and starts a session for it, returning its first
+// token pair.
+func startSession(t *testing.T, s *Service, repo *fakeRepository, u *entity.User) *entity.Token {
+	t.Helper()
+
+	repo.users[u.ID] = u
+
+	tokens, err := s.issueTokens(context.Background(), u, []string{s.cfg.Audience}, nil, entity.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tokens
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	repo := newFakeRepository()
+	s := newTestService(t, repo)
+	ctx := context.Background()
+
+	first := startSession(t, s, repo, &entity.User{ID: 1, Role: "user"})
+
+	second, err := s.Refresh(ctx, first.RefreshToken, entity.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if second.RefreshToken == "" || second.RefreshToken == first.RefreshToken {
+		t.Fatalf("refresh token not rotated: %q", second.RefreshToken)
+	}
+
+	if _, err = s.Refresh(ctx, second.RefreshToken, entity.ClientInfo{}); err != nil {
+		t.Fatalf("rotated token: %v", err)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	repo := newFakeRepository()
+	s := newTestService(t, repo)
+	ctx := context.Background()
+
+	first := startSession(t, s, repo, &entity.User{ID: 1, Role: "user"})
+
+	second, err := s.Refresh(ctx, first.RefreshToken, entity.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = s.Refresh(ctx, first.RefreshToken, entity.ClientInfo{}); !errors.Is(err, RefreshTokenReusedError) {
+		t.Fatalf("reused token: got %v, want %v", err, RefreshTokenReusedError)
+	}
+
+	if !repo.familyRevoked(first.SessionID) {
+		t.Fatal("family not revoked after reuse")
+	}
+
+	if _, err = s.Refresh(ctx, second.RefreshToken, entity.ClientInfo{}); !errors.Is(err, InvalidRefreshTokenError) {
+		t.Fatalf("token rotated before the reuse: got %v, want %v", err, InvalidRefreshTokenError)
+	}
+
+	if !slices.Contains(repo.eventKinds(), entity.EventRefreshTokenReuse) {
+		t.Fatalf("no %s security event, got %v", entity.EventRefreshTokenReuse, repo.eventKinds())
+	}
+}
+
+func TestRefreshUnknownToken(t *testing.T) {
+	s := newTestService(t, newFakeRepository())
+
+	if _, err := s.Refresh(context.Background(), "unknown", entity.ClientInfo{}); !errors.Is(err, InvalidRefreshTokenError) {
+		t.Fatalf("got %v, want %v", err, InvalidRefreshTokenError)
+	}
+}
+
+func TestRefreshRevalidatesUser(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(u *entity.User)
+		want   error
+	}{
+		{"disabled", func(u *entity.User) { u.DisabledAt = sql.NullTime{Time: time.Now(), Valid: true} }, UserDisabledError},
+		{"locked", func(u *entity.User) { u.LockedUntil = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true} }, UserLockedError},
+		{"token version bumped", func(u *entity.User) { u.TokenVersion++ }, InvalidRefreshTokenError},
+		{"deleted", nil, sql.ErrNoRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository()
+			s := newTestService(t, repo)
+
+			u := &entity.User{ID: 1, Role: "user"}
+			first := startSession(t, s, repo, u)
+
+			if tt.change != nil {
+				changed := *u
+				tt.change(&changed)
+				repo.users[u.ID] = &changed
+			} else {
+				delete(repo.users, u.ID)
+			}
+
+			if _, err := s.Refresh(context.Background(), first.RefreshToken, entity.ClientInfo{}); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+
+			if tt.change != nil && !repo.familyRevoked(first.SessionID) {
+				t.Fatal("family not revoked")
+			}
+		})
+	}
+}
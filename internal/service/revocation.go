@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"auth/internal/entity"
+	"auth/internal/logger"
+	"auth/package/utils"
+)
+
+type RevocationRepository interface {
+	CreateRevokedToken(ctx context.Context, t *entity.RevokedToken) error
+	GetRevokedTokens(ctx context.Context) ([]*entity.RevokedToken, error)
+	DeleteExpiredRevokedTokens(ctx context.Context) (int64, error)
+	RevokeRefreshToken(ctx context.Context, id string) (int64, error)
+}
+
+// revocationList caches the revoked jti values in memory. Entries stop
+// counting once the token would have expired anyway.
+type revocationList struct {
+	mu      sync.RWMutex
+	entries map[string]time.Time
+}
+
+func newRevocationList() *revocationList {
+	return &revocationList{entries: make(map[string]time.Time)}
+}
+
+func (l *revocationList) add(jti string, expiresAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if expiresAt.After(l.entries[jti]) {
+		l.entries[jti] = expiresAt
+	}
+}
+
+func (l *revocationList) revoked(jti string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	expiresAt, ok := l.entries[jti]
+	return ok && time.Now().Before(expiresAt)
+}
+
+func (l *revocationList) replace(tokens []*entity.RevokedToken) {
+	entries := make(map[string]time.Time, len(tokens))
+	for _, t := range tokens {
+		entries[t.ID] = t.ExpiresAt
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = entries
+}
+
+// VerifyAccessToken parses an access token and rejects it when its jti is
+// on the revocation list. With jwt.check_token_version it also rejects
+// tokens of deleted or disabled users and clients, and tokens whose version
+// is behind the user's, at the cost of a database read per request.
+func (s *Service) VerifyAccessToken(ctx context.Context, token string) (*entity.AccessClaims, error) {
+	claims, err := s.tokens.GetClaimsAccessToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.revoked.revoked(claims.ID) {
+		logger.FromContext(ctx, s.log).Warn("revoked access token presented", "jti", claims.ID, "id", claims.Sub)
+		return nil, TokenRevokedError
+	}
+
+	if s.cfg.CheckTokenVersion && claims.TokenUse == entity.TokenUseClient {
+		c, err := s.repo.GetOAuthClient(ctx, claims.ClientID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		if err != nil || c.DisabledAt.Valid {
+			logger.FromContext(ctx, s.log).Warn("token of removed client presented", "jti", claims.ID, "client_id", claims.ClientID)
+			return nil, StaleTokenError
+		}
+	} else if s.cfg.CheckTokenVersion {
+		version, err := s.repo.GetUserTokenVersion(ctx, claims.Sub)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		if err != nil || version != claims.Ver {
+			logger.FromContext(ctx, s.log).Warn("stale access token presented", "jti", claims.ID, "id", claims.Sub)
+			return nil, StaleTokenError
+		}
+	}
+
+	return claims, nil
+}
+
+// RevokeToken puts a token on the revocation list, identified either by its
+// jti or by the raw access or refresh token. Without the raw token the
+// expiry is unknown, so the entry is kept for the longest token lifetime.
+func (s *Service) RevokeToken(ctx context.Context, jti, token, reason string) (*entity.RevokedToken, error) {
+	const op = "token.service.Revoke"
+	log := logger.FromContext(ctx, s.log)
+
+	t := &entity.RevokedToken{ID: jti, Reason: reason}
+
+	if token != "" {
+		claims, ok := s.parseAnyToken(ctx, token)
+		if !ok {
+			log.Warn("cannot revoke unparseable token", "op", op)
+			return nil, InvalidTokenError
+		}
+
+		t.ID = claims.id
+		t.UserID = sql.NullInt64{Int64: claims.sub, Valid: claims.sub != 0}
+		t.ExpiresAt = claims.expiresAt
+	} else {
+		t.ExpiresAt = time.Now().Add(max(s.cfg.AccessTTL, s.cfg.RefreshTTL))
+	}
+
+	if err := s.repo.CreateRevokedToken(ctx, t); err != nil {
+		log.Error("failed", "op", op, "error", err)
+		return nil, err
+	}
+
+	// Refresh tokens also have a server-side record; revoking it makes the
+	// refresh fail even on replicas that have not synced the list yet.
+	if _, err := s.repo.RevokeRefreshToken(ctx, t.ID); err != nil {
+		log.Error("failed to revoke refresh token record", "op", op, "jti", t.ID, "error", err)
+		return nil, err
+	}
+
+	s.revoked.add(t.ID, t.ExpiresAt)
+
+	log.Info("token revoked", "op", op, "jti", t.ID, "expires_at", t.ExpiresAt)
+	return t, nil
+}
+
+// anyClaims is the part of access and refresh claims needed to revoke a
+// token.
+type anyClaims struct {
+	id        string
+	sub       int64
+	expiresAt time.Time
+}
+
+// parseAnyToken resolves token as an opaque refresh token or parses it as an
+// access token for any audience this service issues.
+func (s *Service) parseAnyToken(ctx context.Context, token string) (*anyClaims, bool) {
+	if t, err := s.repo.GetRefreshTokenByHash(ctx, utils.HashToken(token)); err == nil {
+		return &anyClaims{id: t.ID, sub: t.UserID, expiresAt: t.ExpiresAt}, true
+	}
+
+	for _, aud := range append([]string{s.cfg.Audience}, s.cfg.AllowedAudiences...) {
+		if claims, err := s.tokens.GetClaimsAccessTokenFor(token, aud); err == nil {
+			return &anyClaims{id: claims.ID, sub: claims.Sub, expiresAt: claims.ExpiresAt.Time}, true
+		}
+	}
+
+	return nil, false
+}
+
+// LoadRevokedTokens replaces the cached revocation list with the one stored
+// in the database.
+func (s *Service) LoadRevokedTokens(ctx context.Context) error {
+	tokens, err := s.repo.GetRevokedTokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.revoked.replace(tokens)
+	return nil
+}
+
+// RunRevocationSync is a background worker that reloads the revocation list
+// so that revocations made on other replicas take effect here, and deletes
+// entries for tokens that have expired.
+func (s *Service) RunRevocationSync(ctx context.Context) {
+	const op = "token.service.RunRevocationSync"
+
+	ticker := time.NewTicker(s.cfg.RevocationSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := s.repo.DeleteExpiredRevokedTokens(ctx); err != nil {
+			s.log.Error("failed to delete expired revoked tokens", "op", op, "error", err)
+		}
+
+		if err := s.LoadRevokedTokens(ctx); err != nil {
+			s.log.Error("failed to reload revoked tokens", "op", op, "error", err)
+		}
+	}
+}
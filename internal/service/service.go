@@ -2,21 +2,55 @@ package service
 
 import (
 	"log/slog"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"auth/internal/config"
+	"auth/internal/entity"
+	"auth/internal/http/lib/jwt"
 )
 
 type Service struct {
-	db   *pgxpool.Pool
-	log  *slog.Logger
-	repo Repository
+	db     *pgxpool.Pool
+	log    *slog.Logger
+	repo   Repository
+	tokens TokenManager
+	cfg    config.JWT
+	oauth  config.OAuth
+	cipher *jwt.KeyCipher
+
+	revoked *revocationList
 }
 
 type Repository interface {
 	UserRepository
 	TokenRepository
+	KeyRepository
+	RevocationRepository
+	SessionRepository
+	ClientRepository
+	AuthorizationRepository
+}
+
+type TokenManager interface {
+	GenerateAccessTokenTTL(claims *entity.AccessClaims, ttl time.Duration) (string, error)
+	GenerateIDToken(claims *entity.IDClaims) (string, error)
+	GetClaimsAccessToken(tokenStr string) (*entity.AccessClaims, error)
+	GetClaimsAccessTokenFor(tokenStr, audience string) (*entity.AccessClaims, error)
+	JWKS() []entity.JWK
+	SetAccessKeys(keys []*jwt.Key) error
+	AccessKeys() []*jwt.Key
 }
 
-func New(db *pgxpool.Pool, log *slog.Logger, repo Repository) *Service {
-	return &Service{db, log, repo}
+func New(db *pgxpool.Pool, log *slog.Logger, repo Repository, tokens TokenManager, cfg config.JWT, oauth config.OAuth) *Service {
+	return &Service{
+		db:      db,
+		log:     log,
+		repo:    repo,
+		tokens:  tokens,
+		cfg:     cfg,
+		oauth:   oauth,
+		revoked: newRevocationList(),
+	}
 }
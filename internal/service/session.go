@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+
+	"auth/internal/entity"
+	"auth/internal/logger"
+)
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, s *entity.Session, t *entity.RefreshToken) error
+	GetUserSessions(ctx context.Context, userID int64) ([]*entity.Session, error)
+	RevokeSession(ctx context.Context, userID int64, id string) (int64, error)
+	DeleteOrphanSessions(ctx context.Context) (int64, error)
+}
+
+// ListSessions returns the active sessions of the user. It fails with
+// sql.ErrNoRows when the user does not exist.
+func (s *Service) ListSessions(ctx context.Context, userID int64) ([]*entity.Session, error) {
+	const op = "session.service.List"
+	log := logger.FromContext(ctx, s.log)
+
+	if err := s.repo.GetUserByID(ctx, &entity.User{ID: userID}); err != nil {
+		log.Error("failed to get user by id", "op", op, "error", err)
+		return nil, err
+	}
+
+	sessions, err := s.repo.GetUserSessions(ctx, userID)
+	if err != nil {
+		log.Error("failed", "op", op, "error", err)
+		return nil, err
+	}
+
+	log.Debug("success", "op", op, "id", userID, "count", len(sessions))
+	return sessions, nil
+}
+
+// RevokeSession ends a session of the user by revoking its refresh tokens.
+// Access tokens already issued for it stay valid until they expire.
+func (s *Service) RevokeSession(ctx context.Context, userID int64, id string) error {
+	const op = "session.service.Revoke"
+	log := logger.FromContext(ctx, s.log)
+
+	revoked, err := s.repo.RevokeSession(ctx, userID, id)
+	if err != nil {
+		log.Error("failed", "op", op, "error", err)
+		return err
+	}
+
+	log.Info("session revoked", "op", op, "id", userID, "session", id, "revoked", revoked)
+	return nil
+}
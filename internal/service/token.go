@@ -2,92 +2,330 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
 
 	"auth/internal/entity"
-	"auth/internal/http/lib/jwt"
+	"auth/internal/logger"
+	"auth/internal/metrics"
+	"auth/internal/repository/postgres"
 	"auth/package/utils"
 )
 
 type TokenRepository interface {
 	GetUserCredentialsByUsername(ctx context.Context, u *entity.User) error
 	CreateUser(ctx context.Context, u *entity.User) error
+	GetRefreshTokenByHash(ctx context.Context, hash string) (*entity.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, hash string, next *entity.RefreshToken) (*entity.RefreshToken, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) (int64, error)
+	RevokeUserRefreshTokens(ctx context.Context, userID int64) (int64, error)
+	DeleteExpiredRefreshTokens(ctx context.Context) (int64, error)
+	CreateSecurityEvent(ctx context.Context, e *entity.SecurityEvent) error
 }
 
-func (s *Service) Register(ctx context.Context, u *entity.User) (*entity.Token, error) {
+func (s *Service) Register(ctx context.Context, u *entity.User, client entity.ClientInfo) (*entity.Token, error) {
 	const op = "user.service.Register"
+	log := logger.FromContext(ctx, s.log)
 
 	var err error
-	u.PasswordHash, err = utils.HashPassword(u.PasswordHash)
+	u.PasswordHash, err = s.hashPassword(u.PasswordHash)
 	if err != nil {
-		s.log.Error("failed", "op", op, "error", err)
+		log.Error("failed", "op", op, "error", err)
+		metrics.AuthOutcome("register", "hash_error")
 		return nil, err
 	}
 
 	if err = s.repo.CreateUser(ctx, u); err != nil {
-		s.log.Error("failed to create user", "op", op, "error", err)
+		log.Error("failed to create user", "op", op, "error", err)
+		if errors.Is(err, postgres.DuplicateError) {
+			metrics.AuthOutcome("register", "duplicate")
+		} else {
+			metrics.AuthOutcome("register", "storage_error")
+		}
 		return nil, err
 	}
 
-	s.log.Debug("user create success", "op", op, "id", u.ID)
+	log.Debug("user create success", "op", op, "id", u.ID)
 
 	var tokens *entity.Token
-	tokens, err = jwt.GenerateAllTokens(u.ID, u.Role)
+	tokens, err = s.issueTokens(ctx, u, []string{s.cfg.Audience}, nil, client)
 	if err != nil {
-		s.log.Error("failed to generate tokens", "op", op, "error", err)
+		log.Error("failed to generate tokens", "op", op, "error", err)
+		metrics.AuthOutcome("register", "token_error")
 		return nil, err
 	}
 
-	s.log.Debug("success", "op", op, "id", u.ID)
+	log.Debug("success", "op", op, "id", u.ID)
+	metrics.AuthOutcome("register", "")
 	return tokens, nil
 }
 
-func (s *Service) Login(ctx context.Context, u *entity.User) (*entity.Token, error) {
+// Login checks the credentials of u and issues tokens for audience, which
+// is empty for this service or one of the allowed audiences. Scope is a
+// space-separated subset of the scopes of the user's role, empty for all.
+func (s *Service) Login(ctx context.Context, u *entity.User, audience, scope string, client entity.ClientInfo) (*entity.Token, error) {
 	const op = "user.service.LoginToken"
+	log := logger.FromContext(ctx, s.log)
 
-	var password = u.PasswordHash
-	err := s.repo.GetUserCredentialsByUsername(ctx, u)
+	aud, err := s.resolveAudience(audience)
 	if err != nil {
-		s.log.Error("failed to get user by id", "op", op, "error", err)
+		log.Warn("login for unknown audience", "op", op, "audience", audience)
+		metrics.AuthOutcome("login", "invalid_audience")
 		return nil, err
 	}
 
-	s.log.Debug("user credentials success", "op", op, "id", u.ID)
+	if err = s.verifyCredentials(ctx, u, "login"); err != nil {
+		return nil, err
+	}
 
-	err = utils.CheckPasswordHash(u.PasswordHash, password)
+	scopes, err := resolveScope(u.Role, scope)
 	if err != nil {
-		s.log.Error("failed to check password", "op", op, "error", err)
+		log.Warn("login with scope beyond role", "op", op, "id", u.ID, "scope", scope)
+		metrics.AuthOutcome("login", "invalid_scope")
 		return nil, err
 	}
 
 	var tokens *entity.Token
-	tokens, err = jwt.GenerateAllTokens(u.ID, u.Role)
+	tokens, err = s.issueTokens(ctx, u, aud, scopes, client)
 	if err != nil {
-		s.log.Error("failed to generate tokens", "op", op, "error", err)
+		log.Error("failed to generate tokens", "op", op, "error", err)
+		metrics.AuthOutcome("login", "token_error")
 		return nil, err
 	}
 
-	s.log.Debug("success", "op", op, "id", u.ID)
+	log.Debug("success", "op", op, "id", u.ID)
+	metrics.AuthOutcome("login", "")
 	return tokens, nil
 }
 
-func (s *Service) Refresh(token string) (string, error) {
+// verifyCredentials loads the user named by u.Username and checks that they
+// are active and that u.PasswordHash, the plain password, matches. The
+// outcome is recorded under operation.
+func (s *Service) verifyCredentials(ctx context.Context, u *entity.User, operation string) error {
+	const op = "user.service.VerifyCredentials"
+	log := logger.FromContext(ctx, s.log)
+
+	var password = u.PasswordHash
+	err := s.repo.GetUserCredentialsByUsername(ctx, u)
+	if err != nil {
+		log.Error("failed to get user by id", "op", op, "error", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			metrics.AuthOutcome(operation, "user_not_found")
+		} else {
+			metrics.AuthOutcome(operation, "storage_error")
+		}
+		return err
+	}
+
+	log.Debug("user credentials success", "op", op, "id", u.ID)
+
+	if err = checkActive(u); err != nil {
+		log.Warn("login attempt for inactive user", "op", op, "id", u.ID, "error", err)
+		metrics.AuthOutcome(operation, inactiveOutcome(err))
+		return err
+	}
+
+	err = s.checkPassword(u.PasswordHash, password)
+	if err != nil {
+		log.Error("failed to check password", "op", op, "error", err)
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			metrics.AuthOutcome(operation, "invalid_password")
+		} else {
+			metrics.AuthOutcome(operation, "hash_error")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Refresh exchanges an opaque refresh token for a new token pair and
+// invalidates it. Presenting a refresh token that was already exchanged
+// revokes its whole family, since either the client or an attacker holds a
+// stolen copy. Revoked tokens are marked on their record, so no revocation
+// list lookup is needed here. The claims are built from the user as stored
+// now. A deleted user yields sql.ErrNoRows, and the family is revoked when
+// the user is disabled or locked or their token version has moved on.
+// Token lifetimes follow the OAuth client that started the session, if any.
+func (s *Service) Refresh(ctx context.Context, token string, client entity.ClientInfo) (*entity.Token, error) {
 	const op = "user.service.RefreshToken"
+	log := logger.FromContext(ctx, s.log)
+
+	hash := utils.HashToken(token)
+
+	// The next token is created with its expiry before the rotation, so the
+	// session's client is looked up ahead of it. RotateRefreshToken checks
+	// the token again.
+	accessTTL, refreshTTL := s.cfg.AccessTTL, s.cfg.RefreshTTL
+	if t, err := s.repo.GetRefreshTokenByHash(ctx, hash); err == nil {
+		accessTTL, refreshTTL = s.tokenTTL(ctx, t.OAuthClientID)
+	}
+
+	next, refreshToken, err := s.newRefreshToken(client, refreshTTL)
+	if err != nil {
+		log.Error("failed to create refresh token", "op", op, "error", err)
+		metrics.AuthOutcome("refresh", "token_error")
+		return nil, err
+	}
+
+	old, err := s.repo.RotateRefreshToken(ctx, hash, next)
+	switch {
+	case errors.Is(err, postgres.RefreshTokenUsedError):
+		log.Warn("refresh token reuse detected, revoking family", "op", op, "id", old.UserID, "family", old.FamilyID)
+		metrics.AuthOutcome("refresh", "reused")
+		s.revokeFamily(ctx, old, entity.EventRefreshTokenReuse, client)
+		return nil, RefreshTokenReusedError
+	case errors.Is(err, postgres.RefreshTokenRevokedError):
+		metrics.AuthOutcome("refresh", "revoked")
+		return nil, InvalidRefreshTokenError
+	case errors.Is(err, postgres.RefreshTokenExpiredError):
+		metrics.AuthOutcome("refresh", "expired")
+		return nil, InvalidRefreshTokenError
+	case errors.Is(err, sql.ErrNoRows):
+		metrics.AuthOutcome("refresh", "unknown_token")
+		return nil, InvalidRefreshTokenError
+	case err != nil:
+		log.Error("failed to rotate refresh token", "op", op, "error", err)
+		metrics.AuthOutcome("refresh", "storage_error")
+		return nil, err
+	}
+
+	log.Debug("refresh token success", "op", op, "id", next.UserID)
+
+	u := &entity.User{ID: next.UserID}
+	err = s.repo.GetUserByID(ctx, u)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Warn("refresh for deleted user", "op", op, "id", next.UserID)
+		metrics.AuthOutcome("refresh", "user_not_found")
+		return nil, err
+	}
+
+	if err != nil {
+		log.Error("failed to get user by id", "op", op, "error", err)
+		metrics.AuthOutcome("refresh", "storage_error")
+		return nil, err
+	}
 
-	claims, err := jwt.GetClaimsRefreshToken(token)
+	if err = checkActive(u); err != nil {
+		log.Warn("refresh for inactive user, revoking family", "op", op, "id", u.ID, "error", err)
+		metrics.AuthOutcome("refresh", inactiveOutcome(err))
+		if _, revokeErr := s.repo.RevokeRefreshTokenFamily(ctx, next.FamilyID); revokeErr != nil {
+			log.Error("failed to revoke refresh token family", "op", op, "error", revokeErr)
+		}
+		return nil, err
+	}
+
+	if u.TokenVersion != next.TokenVersion {
+		log.Info("refresh token predates token version, revoking family", "op", op, "id", u.ID, "family", next.FamilyID)
+		metrics.AuthOutcome("refresh", "stale_version")
+		if _, err = s.repo.RevokeRefreshTokenFamily(ctx, next.FamilyID); err != nil {
+			log.Error("failed to revoke refresh token family", "op", op, "error", err)
+		}
+		return nil, InvalidRefreshTokenError
+	}
+
+	tokens, err := s.signAccessToken(accessClaims(u, next), accessTTL)
 	if err != nil {
-		s.log.Error("failed to parse refresh token", "op", op, "error", err)
-		return "", err
+		log.Error("failed to generate access token", "op", op, "error", err)
+		metrics.AuthOutcome("refresh", "token_error")
+		return nil, err
 	}
 
-	s.log.Debug("refresh token success", "op", op, "id", claims.Sub)
+	tokens.RefreshToken = refreshToken
+
+	log.Debug("success", "op", op, "id", u.ID)
+	metrics.AuthOutcome("refresh", "")
+	return tokens, nil
+}
+
+// Logout revokes the refresh token and every token rotated from the same
+// login.
+func (s *Service) Logout(ctx context.Context, token string) error {
+	const op = "user.service.Logout"
+	log := logger.FromContext(ctx, s.log)
 
-	var accessToken string
-	accessToken, err = jwt.GenerateAccessToken(claims.Sub, claims.Role)
+	t, err := s.repo.GetRefreshTokenByHash(ctx, utils.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		metrics.AuthOutcome("logout", "invalid_token")
+		return InvalidRefreshTokenError
+	}
+
+	if err != nil {
+		log.Error("failed to get refresh token", "op", op, "error", err)
+		metrics.AuthOutcome("logout", "storage_error")
+		return err
+	}
+
+	revoked, err := s.repo.RevokeRefreshTokenFamily(ctx, t.FamilyID)
 	if err != nil {
-		s.log.Error("failed to generate access token", "op", op, "error", err)
-		return "", err
+		log.Error("failed to revoke refresh token family", "op", op, "error", err)
+		metrics.AuthOutcome("logout", "storage_error")
+		return err
+	}
+
+	log.Debug("success", "op", op, "id", t.UserID, "family", t.FamilyID, "revoked", revoked)
+	metrics.AuthOutcome("logout", "")
+	return nil
+}
+
+// LogoutAll revokes every refresh token of the user, ending all of their
+// sessions, and bumps the token version. Access tokens already issued stay
+// valid until they expire unless revoked separately or token versions are
+// checked on access. actorID is the user who asked for it, recorded in the
+// security event; zero when an OAuth client did.
+func (s *Service) LogoutAll(ctx context.Context, userID, actorID int64) error {
+	const op = "user.service.LogoutAll"
+	log := logger.FromContext(ctx, s.log)
+
+	if err := s.repo.GetUserByID(ctx, &entity.User{ID: userID}); err != nil {
+		log.Error("failed to get user by id", "op", op, "error", err)
+		metrics.AuthOutcome("logout_all", "user_not_found")
+		return err
+	}
+
+	revoked, err := s.repo.RevokeUserRefreshTokens(ctx, userID)
+	if err != nil {
+		log.Error("failed to revoke refresh tokens", "op", op, "error", err)
+		metrics.AuthOutcome("logout_all", "storage_error")
+		return err
+	}
+
+	if _, err = s.repo.BumpUserTokenVersion(ctx, userID); err != nil {
+		log.Error("failed to bump token version", "op", op, "error", err)
+		metrics.AuthOutcome("logout_all", "storage_error")
+		return err
+	}
+
+	event := &entity.SecurityEvent{
+		UserID:  sql.NullInt64{Int64: userID, Valid: true},
+		Kind:    entity.EventLogoutAll,
+		Details: map[string]any{"revoked": revoked},
+	}
+
+	if actorID != 0 {
+		event.Details["actor_id"] = actorID
+	}
+
+	if err = s.repo.CreateSecurityEvent(ctx, event); err != nil {
+		log.Error("failed to record security event", "op", op, "error", err)
+	}
+
+	log.Info("all sessions revoked", "op", op, "id", userID, "revoked", revoked)
+	metrics.AuthOutcome("logout_all", "")
+	return nil
+}
+
+func (s *Service) JWKS() []entity.JWK {
+	return s.tokens.JWKS()
+}
+
+// inactiveOutcome is the metrics reason for an inactive user error.
+func inactiveOutcome(err error) string {
+	if errors.Is(err, UserLockedError) {
+		return "user_locked"
 	}
 
-	s.log.Debug("success", "op", op, "id", claims.Sub)
-	return accessToken, nil
+	return "user_disabled"
 }
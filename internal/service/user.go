@@ -2,9 +2,11 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"auth/internal/entity"
-	"auth/package/utils"
+	"auth/internal/logger"
 )
 
 type UserRepository interface {
@@ -13,78 +15,185 @@ type UserRepository interface {
 	GetAllUsers(ctx context.Context) ([]*entity.User, error)
 	UpdateUserByID(ctx context.Context, u *entity.User) error
 	DeleteUserByID(ctx context.Context, id int64) error
+	GetUserByUsername(ctx context.Context, u *entity.User) error
+	UpdateUserRole(ctx context.Context, u *entity.User) error
+	UpdateUserPassword(ctx context.Context, u *entity.User) error
+	DisableUserByID(ctx context.Context, id int64) error
+	LockUserByID(ctx context.Context, id int64, until sql.NullTime) error
+	BumpUserTokenVersion(ctx context.Context, id int64) (int64, error)
+	GetUserTokenVersion(ctx context.Context, id int64) (int64, error)
 }
 
 func (s *Service) CreateUser(ctx context.Context, u *entity.User) error {
 	const op = "user.service.Create"
+	log := logger.FromContext(ctx, s.log)
 
 	var err error
-	u.PasswordHash, err = utils.HashPassword(u.PasswordHash)
+	u.PasswordHash, err = s.hashPassword(u.PasswordHash)
 	if err != nil {
-		s.log.Error("failed", "op", op, "error", err)
+		log.Error("failed", "op", op, "error", err)
 		return err
 	}
 
 	if err = s.repo.CreateUser(ctx, u); err != nil {
-		s.log.Error("failed", "op", op, "error", err)
+		log.Error("failed", "op", op, "error", err)
 		return err
 	}
 
-	s.log.Debug("success", "op", op, "id", u.ID)
+	log.Debug("success", "op", op, "id", u.ID)
 
 	return nil
 }
 
 func (s *Service) GetUserByID(ctx context.Context, u *entity.User) error {
 	const op = "user.service.GetByID"
+	log := logger.FromContext(ctx, s.log)
 
 	err := s.repo.GetUserByID(ctx, u)
 	if err != nil {
-		s.log.Error("failed to get book by id", "op", op, "error", err)
+		log.Error("failed to get book by id", "op", op, "error", err)
 		return err
 	}
 
-	s.log.Debug("success", "op", op, "id", u.ID)
+	log.Debug("success", "op", op, "id", u.ID)
 
 	return nil
 }
 
 func (s *Service) GetAllUsers(ctx context.Context) ([]*entity.User, error) {
 	const op = "user.service.GetAll"
+	log := logger.FromContext(ctx, s.log)
 
 	books, err := s.repo.GetAllUsers(ctx)
 	if err != nil {
-		s.log.Error("failed to get all books", "op", op, "error", err)
+		log.Error("failed to get all books", "op", op, "error", err)
 		return nil, err
 	}
 
-	s.log.Debug("success", "op", op, "count", len(books))
+	log.Debug("success", "op", op, "count", len(books))
 	return books, nil
 }
 
 func (s *Service) UpdateUserByID(ctx context.Context, u *entity.User) error {
 	const op = "user.service.Update"
+	log := logger.FromContext(ctx, s.log)
 
 	err := s.repo.UpdateUserByID(ctx, u)
 	if err != nil {
-		s.log.Error("failed", "op", op, "error", err)
+		log.Error("failed", "op", op, "error", err)
 		return err
 	}
 
-	s.log.Debug("success", "op", op, "id", u.ID)
+	log.Debug("success", "op", op, "id", u.ID)
 
 	return nil
 }
 
 func (s *Service) DeleteUserByID(ctx context.Context, id int64) error {
 	const op = "book.service.DeleteByID"
+	log := logger.FromContext(ctx, s.log)
 
 	if err := s.repo.DeleteUserByID(ctx, id); err != nil {
-		s.log.Error("failed", "op", op, "error", err)
+		log.Error("failed", "op", op, "error", err)
 		return err
 	}
 
-	s.log.Debug("success", "op", op, "id", id)
+	log.Debug("success", "op", op, "id", id)
+
+	return nil
+}
+
+func (s *Service) GetUserByUsername(ctx context.Context, u *entity.User) error {
+	const op = "user.service.GetByUsername"
+	log := logger.FromContext(ctx, s.log)
+
+	if err := s.repo.GetUserByUsername(ctx, u); err != nil {
+		log.Error("failed", "op", op, "error", err)
+		return err
+	}
+
+	log.Debug("success", "op", op, "id", u.ID)
+
+	return nil
+}
+
+func (s *Service) SetUserRole(ctx context.Context, u *entity.User) error {
+	const op = "user.service.SetRole"
+	log := logger.FromContext(ctx, s.log)
+
+	if err := s.repo.UpdateUserRole(ctx, u); err != nil {
+		log.Error("failed", "op", op, "error", err)
+		return err
+	}
+
+	log.Info("user role changed", "op", op, "id", u.ID, "role", u.Role)
+
+	return nil
+}
+
+func (s *Service) ResetPassword(ctx context.Context, u *entity.User) error {
+	const op = "user.service.ResetPassword"
+	log := logger.FromContext(ctx, s.log)
+
+	var err error
+	u.PasswordHash, err = s.hashPassword(u.PasswordHash)
+	if err != nil {
+		log.Error("failed", "op", op, "error", err)
+		return err
+	}
+
+	if err = s.repo.UpdateUserPassword(ctx, u); err != nil {
+		log.Error("failed", "op", op, "error", err)
+		return err
+	}
+
+	log.Info("user password reset", "op", op, "id", u.ID)
+
+	return nil
+}
+
+func (s *Service) DisableUserByID(ctx context.Context, id int64) error {
+	const op = "user.service.Disable"
+	log := logger.FromContext(ctx, s.log)
+
+	if err := s.repo.DisableUserByID(ctx, id); err != nil {
+		log.Error("failed", "op", op, "error", err)
+		return err
+	}
+
+	log.Info("user disabled", "op", op, "id", id)
+
+	return nil
+}
+
+// LockUser keeps the user from logging in and refreshing tokens until the
+// given time. A zero time unlocks the user.
+func (s *Service) LockUser(ctx context.Context, id int64, until time.Time) error {
+	const op = "user.service.Lock"
+	log := logger.FromContext(ctx, s.log)
+
+	if err := s.repo.LockUserByID(ctx, id, sql.NullTime{Time: until, Valid: !until.IsZero()}); err != nil {
+		log.Error("failed", "op", op, "error", err)
+		return err
+	}
+
+	if until.IsZero() {
+		log.Info("user unlocked", "op", op, "id", id)
+	} else {
+		log.Info("user locked", "op", op, "id", id, "until", until)
+	}
+
+	return nil
+}
+
+// checkActive rejects users that are disabled or locked right now.
+func checkActive(u *entity.User) error {
+	switch {
+	case u.DisabledAt.Valid:
+		return UserDisabledError
+	case u.LockedUntil.Valid && time.Now().Before(u.LockedUntil.Time):
+		return UserLockedError
+	}
 
 	return nil
 }
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS users;
+
+DROP TYPE IF EXISTS user_role;
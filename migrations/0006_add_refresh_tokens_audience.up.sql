@@ -0,0 +1 @@
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS audience TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,7 @@
+DELETE FROM refresh_tokens;
+
+ALTER TABLE refresh_tokens
+    DROP COLUMN token_hash,
+    DROP COLUMN client_id,
+    DROP COLUMN user_agent,
+    DROP COLUMN ip;
@@ -0,0 +1,11 @@
+-- Refresh tokens issued as JWTs cannot be matched to a hash, so their
+-- records are dropped and those sessions have to log in again.
+DELETE FROM refresh_tokens;
+
+ALTER TABLE refresh_tokens
+    ADD COLUMN token_hash VARCHAR(64) NOT NULL,
+    ADD COLUMN client_id VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    ADD COLUMN ip VARCHAR(45) NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS refresh_tokens_token_hash ON refresh_tokens (token_hash);
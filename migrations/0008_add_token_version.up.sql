@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS token_version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    client_id VARCHAR(100) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id ON sessions (user_id);
+
+-- Every refresh token family issued so far becomes a session, described by
+-- its first and its latest token.
+INSERT INTO sessions (id, user_id, client_id, user_agent, ip, created_at, last_seen_at)
+SELECT DISTINCT ON (family_id)
+       family_id, user_id, client_id, user_agent, ip,
+       MIN(created_at) OVER (PARTITION BY family_id), created_at
+FROM refresh_tokens
+ORDER BY family_id, created_at DESC;
+
+ALTER TABLE refresh_tokens
+    ADD CONSTRAINT refresh_tokens_family_id_fkey
+    FOREIGN KEY (family_id) REFERENCES sessions (id) ON DELETE CASCADE;
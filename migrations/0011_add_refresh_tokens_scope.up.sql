@@ -0,0 +1,2 @@
+-- An empty scope grants every scope of the user's role at refresh time.
+ALTER TABLE refresh_tokens ADD COLUMN scope TEXT[] NOT NULL DEFAULT '{}';
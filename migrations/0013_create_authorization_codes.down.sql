@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS authorization_codes;
+
+ALTER TABLE oauth_clients
+    DROP COLUMN public,
+    DROP COLUMN redirect_uris;
@@ -0,0 +1,22 @@
+-- Public clients, such as single-page apps, have no secret and must use
+-- PKCE; their secret_hash is empty.
+ALTER TABLE oauth_clients
+    ADD COLUMN redirect_uris TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN public BOOLEAN NOT NULL DEFAULT false;
+
+-- Exchanged codes are kept for a day after they expire so that a second
+-- exchange is detected, and the session they started can then be revoked.
+CREATE TABLE IF NOT EXISTS authorization_codes (
+    code_hash VARCHAR(64) PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL REFERENCES oauth_clients (id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    redirect_uri TEXT NOT NULL,
+    scope TEXT[] NOT NULL DEFAULT '{}',
+    code_challenge VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    used_at TIMESTAMPTZ,
+    session_id VARCHAR(64)
+);
+
+CREATE INDEX IF NOT EXISTS authorization_codes_expires_at ON authorization_codes (expires_at);
@@ -0,0 +1,3 @@
+ALTER TABLE authorization_codes
+    DROP COLUMN auth_time,
+    DROP COLUMN nonce;
@@ -0,0 +1,5 @@
+-- OpenID Connect requests carry a nonce that the ID token must echo, and
+-- ID tokens report when the user signed in.
+ALTER TABLE authorization_codes
+    ADD COLUMN nonce TEXT NOT NULL DEFAULT '',
+    ADD COLUMN auth_time TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
@@ -0,0 +1,5 @@
+ALTER TABLE oauth_clients
+    DROP COLUMN updated_at,
+    DROP COLUMN refresh_ttl,
+    DROP COLUMN access_ttl,
+    DROP COLUMN grant_types;
@@ -0,0 +1,14 @@
+-- Clients are limited to the grants they are registered for and may
+-- override the configured token lifetimes; a zero interval keeps the
+-- default.
+ALTER TABLE oauth_clients
+    ADD COLUMN grant_types TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN access_ttl INTERVAL NOT NULL DEFAULT '0',
+    ADD COLUMN refresh_ttl INTERVAL NOT NULL DEFAULT '0',
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE oauth_clients SET grant_types = CASE
+    WHEN public THEN '{authorization_code}'::TEXT[]
+    WHEN cardinality(redirect_uris) > 0 THEN '{client_credentials,authorization_code}'::TEXT[]
+    ELSE '{client_credentials}'::TEXT[]
+END;
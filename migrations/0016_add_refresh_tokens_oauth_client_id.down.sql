@@ -0,0 +1 @@
+ALTER TABLE refresh_tokens DROP COLUMN oauth_client_id;
@@ -0,0 +1,5 @@
+-- client_id is a label the caller chose at login. oauth_client_id names the
+-- registered client that authenticated when the session started, whose
+-- token lifetime overrides apply.
+ALTER TABLE refresh_tokens
+    ADD COLUMN oauth_client_id VARCHAR(64) REFERENCES oauth_clients (id) ON DELETE SET NULL;